@@ -0,0 +1,46 @@
+// Package agents defines named bundles of system prompt + scoped toolset
+// that can be selected per request instead of exposing every configured
+// MCP tool in every context.
+package agents
+
+import "mcphost-server/pkg/llm"
+
+// Agent is a named bundle of a system prompt, an allow-list of
+// "serverName__toolName" entries it may call, and optional default
+// model overrides.
+type Agent struct {
+	SystemPrompt string   `json:"systemPrompt"`
+	AllowedTools []string `json:"allowedTools"`
+	Model        string   `json:"model,omitempty"`
+}
+
+// IsToolAllowed reports whether namespacedName ("serverName__toolName")
+// is permitted for this agent. An empty allow-list permits every tool,
+// matching the pre-agent default of exposing everything.
+func (a *Agent) IsToolAllowed(namespacedName string) bool {
+	if a == nil || len(a.AllowedTools) == 0 {
+		return true
+	}
+	for _, allowed := range a.AllowedTools {
+		if allowed == namespacedName {
+			return true
+		}
+	}
+	return false
+}
+
+// FilterTools narrows tools down to the subset this agent is allowed to
+// call. A nil agent passes every tool through unchanged.
+func (a *Agent) FilterTools(tools []llm.Tool) []llm.Tool {
+	if a == nil || len(a.AllowedTools) == 0 {
+		return tools
+	}
+
+	filtered := make([]llm.Tool, 0, len(tools))
+	for _, tool := range tools {
+		if a.IsToolAllowed(tool.Name) {
+			filtered = append(filtered, tool)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,332 @@
+// Package builtintools implements a small, in-process "virtual" MCP
+// server exposing filesystem tools (dir_tree, read_file, modify_file)
+// rooted at a configurable workspace, so the model can inspect and
+// edit local files without an external MCP subprocess.
+package builtintools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// ServerName is the namespace these tools are registered under, so
+// they appear as "builtin__dir_tree", "builtin__read_file", etc.
+const ServerName = "builtin"
+
+const defaultMaxDepth = 5
+
+// Server is the in-process implementation of the builtin toolbox. It
+// satisfies the same CallTool/ListTools/Close shape as an MCP client,
+// so it can sit in the same client registry as remote servers.
+type Server struct {
+	workspaceRoot string
+}
+
+// New resolves workspaceRoot to an absolute, symlink-free path and
+// returns a Server rooted there. Every tool call is rejected if it
+// would escape this root. Resolving symlinks up front (rather than
+// only on each resolved call path) matters because resolvePath compares
+// against workspaceRoot post-EvalSymlinks - if the root itself sits
+// behind a symlink (e.g. macOS's /tmp), every legitimate path under it
+// would otherwise fail the prefix check.
+func New(workspaceRoot string) (*Server, error) {
+	root, err := filepath.Abs(workspaceRoot)
+	if err != nil {
+		return nil, fmt.Errorf("error resolving workspace root: %w", err)
+	}
+
+	if resolved, err := filepath.EvalSymlinks(root); err == nil {
+		root = resolved
+	} else if !os.IsNotExist(err) {
+		return nil, fmt.Errorf("error resolving workspace root: %w", err)
+	}
+
+	return &Server{workspaceRoot: root}, nil
+}
+
+func (s *Server) ListTools(ctx context.Context, req mcp.ListToolsRequest) (*mcp.ListToolsResult, error) {
+	return &mcp.ListToolsResult{
+		Tools: []mcp.Tool{
+			{
+				Name:        "dir_tree",
+				Description: "Return a nested JSON tree of a directory under the workspace root.",
+				InputSchema: mcp.ToolInputSchema{
+					Type: "object",
+					Properties: map[string]interface{}{
+						"relative_path": map[string]interface{}{"type": "string"},
+						"depth":         map[string]interface{}{"type": "integer"},
+					},
+					Required: []string{"relative_path"},
+				},
+			},
+			{
+				Name:        "read_file",
+				Description: "Read a file under the workspace root, optionally limited to a line range.",
+				InputSchema: mcp.ToolInputSchema{
+					Type: "object",
+					Properties: map[string]interface{}{
+						"path":       map[string]interface{}{"type": "string"},
+						"start_line": map[string]interface{}{"type": "integer"},
+						"end_line":   map[string]interface{}{"type": "integer"},
+					},
+					Required: []string{"path"},
+				},
+			},
+			{
+				Name:        "modify_file",
+				Description: "Apply non-overlapping line-range edits to a file under the workspace root.",
+				InputSchema: mcp.ToolInputSchema{
+					Type: "object",
+					Properties: map[string]interface{}{
+						"path":  map[string]interface{}{"type": "string"},
+						"edits": map[string]interface{}{"type": "array"},
+					},
+					Required: []string{"path", "edits"},
+				},
+			},
+		},
+	}, nil
+}
+
+func (s *Server) Close() error { return nil }
+
+func (s *Server) CallTool(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	switch req.Params.Name {
+	case "dir_tree":
+		return s.dirTree(req.Params.Arguments)
+	case "read_file":
+		return s.readFile(req.Params.Arguments)
+	case "modify_file":
+		return s.modifyFile(req.Params.Arguments)
+	default:
+		return nil, fmt.Errorf("unknown builtin tool: %s", req.Params.Name)
+	}
+}
+
+// resolvePath joins relativePath onto the workspace root and rejects
+// it if it escapes the root via ".." or a symlink.
+func (s *Server) resolvePath(relativePath string) (string, error) {
+	joined := filepath.Join(s.workspaceRoot, relativePath)
+
+	resolved, err := filepath.EvalSymlinks(joined)
+	if err != nil {
+		if os.IsNotExist(err) {
+			resolved = joined
+		} else {
+			return "", fmt.Errorf("error resolving path %s: %w", relativePath, err)
+		}
+	}
+
+	rootWithSep := s.workspaceRoot + string(os.PathSeparator)
+	if resolved != s.workspaceRoot && !strings.HasPrefix(resolved, rootWithSep) {
+		return "", fmt.Errorf("path escapes workspace root: %s", relativePath)
+	}
+	return resolved, nil
+}
+
+func textResult(text string) *mcp.CallToolResult {
+	return &mcp.CallToolResult{
+		Content: []interface{}{
+			map[string]interface{}{"type": "text", "text": text},
+		},
+	}
+}
+
+type treeNode struct {
+	Name     string     `json:"name"`
+	IsDir    bool       `json:"is_dir"`
+	Children []treeNode `json:"children,omitempty"`
+}
+
+func (s *Server) dirTree(args map[string]interface{}) (*mcp.CallToolResult, error) {
+	relativePath, _ := args["relative_path"].(string)
+
+	depth := defaultMaxDepth
+	if d, ok := args["depth"].(float64); ok {
+		depth = int(d)
+	}
+	if depth > defaultMaxDepth {
+		depth = defaultMaxDepth
+	}
+
+	root, err := s.resolvePath(relativePath)
+	if err != nil {
+		return nil, err
+	}
+
+	node, err := buildTree(root, filepath.Base(root), depth)
+	if err != nil {
+		return nil, fmt.Errorf("error building dir tree: %w", err)
+	}
+
+	encoded, err := marshalTree(node)
+	if err != nil {
+		return nil, err
+	}
+	return textResult(encoded), nil
+}
+
+func marshalTree(node treeNode) (string, error) {
+	data, err := json.Marshal(node)
+	if err != nil {
+		return "", fmt.Errorf("error marshaling dir tree: %w", err)
+	}
+	return string(data), nil
+}
+
+func buildTree(path, name string, depth int) (treeNode, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return treeNode{}, err
+	}
+
+	node := treeNode{Name: name, IsDir: info.IsDir()}
+	if !info.IsDir() || depth <= 0 {
+		return node, nil
+	}
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return treeNode{}, err
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+
+	for _, entry := range entries {
+		child, err := buildTree(filepath.Join(path, entry.Name()), entry.Name(), depth-1)
+		if err != nil {
+			return treeNode{}, err
+		}
+		node.Children = append(node.Children, child)
+	}
+	return node, nil
+}
+
+func (s *Server) readFile(args map[string]interface{}) (*mcp.CallToolResult, error) {
+	path, _ := args["path"].(string)
+
+	resolved, err := s.resolvePath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(resolved)
+	if err != nil {
+		return nil, fmt.Errorf("error reading file %s: %w", path, err)
+	}
+
+	lines := strings.Split(string(data), "\n")
+	startLine := 1
+	endLine := len(lines)
+	if v, ok := args["start_line"].(float64); ok {
+		startLine = int(v)
+	}
+	if v, ok := args["end_line"].(float64); ok {
+		endLine = int(v)
+	}
+	if startLine < 1 {
+		startLine = 1
+	}
+	if endLine > len(lines) {
+		endLine = len(lines)
+	}
+	if startLine > endLine {
+		return textResult(""), nil
+	}
+
+	return textResult(strings.Join(lines[startLine-1:endLine], "\n")), nil
+}
+
+// lineEdit is a single non-overlapping line-range replacement.
+type lineEdit struct {
+	StartLine   int    `json:"start_line"`
+	EndLine     int    `json:"end_line"`
+	Replacement string `json:"replacement"`
+}
+
+func (s *Server) modifyFile(args map[string]interface{}) (*mcp.CallToolResult, error) {
+	path, _ := args["path"].(string)
+	resolved, err := s.resolvePath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	rawEdits, ok := args["edits"].([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("edits must be an array")
+	}
+
+	edits := make([]lineEdit, 0, len(rawEdits))
+	for _, raw := range rawEdits {
+		m, ok := raw.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("invalid edit entry")
+		}
+		edit := lineEdit{Replacement: fmt.Sprintf("%v", m["replacement"])}
+		if v, ok := m["start_line"].(float64); ok {
+			edit.StartLine = int(v)
+		}
+		if v, ok := m["end_line"].(float64); ok {
+			edit.EndLine = int(v)
+		}
+		edits = append(edits, edit)
+	}
+
+	// Sort descending by start line so earlier edits don't shift the
+	// line numbers later edits refer to, and reject overlaps up front.
+	sort.Slice(edits, func(i, j int) bool { return edits[i].StartLine > edits[j].StartLine })
+	for i := 1; i < len(edits); i++ {
+		if edits[i].EndLine >= edits[i-1].StartLine {
+			return nil, fmt.Errorf("overlapping edits at lines %d-%d and %d-%d",
+				edits[i].StartLine, edits[i].EndLine, edits[i-1].StartLine, edits[i-1].EndLine)
+		}
+	}
+
+	info, err := os.Stat(resolved)
+	if err != nil {
+		return nil, fmt.Errorf("error stating file %s: %w", path, err)
+	}
+
+	data, err := os.ReadFile(resolved)
+	if err != nil {
+		return nil, fmt.Errorf("error reading file %s: %w", path, err)
+	}
+	lines := strings.Split(string(data), "\n")
+
+	for _, edit := range edits {
+		if edit.StartLine < 1 || edit.EndLine > len(lines) || edit.StartLine > edit.EndLine {
+			return nil, fmt.Errorf("edit out of range: %d-%d", edit.StartLine, edit.EndLine)
+		}
+		replacement := strings.Split(edit.Replacement, "\n")
+		lines = append(lines[:edit.StartLine-1], append(replacement, lines[edit.EndLine:]...)...)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(resolved), ".modify_file-*")
+	if err != nil {
+		return nil, fmt.Errorf("error creating temp file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.WriteString(strings.Join(lines, "\n")); err != nil {
+		tmp.Close()
+		return nil, fmt.Errorf("error writing temp file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return nil, fmt.Errorf("error closing temp file: %w", err)
+	}
+	if err := os.Chmod(tmp.Name(), info.Mode()); err != nil {
+		return nil, fmt.Errorf("error preserving file mode for %s: %w", path, err)
+	}
+
+	if err := os.Rename(tmp.Name(), resolved); err != nil {
+		return nil, fmt.Errorf("error replacing file %s: %w", path, err)
+	}
+
+	return textResult(fmt.Sprintf("applied %d edit(s) to %s", len(edits), path)), nil
+}
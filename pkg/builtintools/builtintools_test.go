@@ -0,0 +1,143 @@
+package builtintools
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestResolvePathRoot(t *testing.T) {
+	root := t.TempDir()
+	s, err := New(root)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	resolved, err := s.resolvePath("")
+	if err != nil {
+		t.Fatalf("resolvePath(\"\"): %v", err)
+	}
+	if resolved != s.workspaceRoot {
+		t.Errorf("resolvePath(\"\") = %q, want workspace root %q", resolved, s.workspaceRoot)
+	}
+}
+
+func TestResolvePathEscapeViaDotDot(t *testing.T) {
+	root := t.TempDir()
+	s, err := New(root)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	if _, err := s.resolvePath("../outside"); err == nil {
+		t.Fatal("resolvePath(\"../outside\") succeeded, want error")
+	}
+}
+
+func TestResolvePathEscapeViaSymlink(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symlinks require elevated privileges on windows")
+	}
+
+	root := t.TempDir()
+	outside := t.TempDir()
+	if err := os.WriteFile(filepath.Join(outside, "secret.txt"), []byte("secret"), 0o644); err != nil {
+		t.Fatalf("error seeding outside file: %v", err)
+	}
+
+	link := filepath.Join(root, "escape")
+	if err := os.Symlink(outside, link); err != nil {
+		t.Fatalf("error creating symlink: %v", err)
+	}
+
+	s, err := New(root)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	if _, err := s.resolvePath("escape/secret.txt"); err == nil {
+		t.Fatal("resolvePath through a symlinked escape succeeded, want error")
+	}
+}
+
+func TestResolvePathWithinRoot(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "inside.txt"), []byte("data"), 0o644); err != nil {
+		t.Fatalf("error seeding file: %v", err)
+	}
+
+	s, err := New(root)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	resolved, err := s.resolvePath("inside.txt")
+	if err != nil {
+		t.Fatalf("resolvePath(\"inside.txt\"): %v", err)
+	}
+	if filepath.Base(resolved) != "inside.txt" {
+		t.Errorf("resolvePath(\"inside.txt\") = %q, want a path ending in inside.txt", resolved)
+	}
+}
+
+func TestModifyFileRejectsOverlappingEdits(t *testing.T) {
+	root := t.TempDir()
+	path := filepath.Join(root, "file.txt")
+	if err := os.WriteFile(path, []byte("one\ntwo\nthree\nfour\n"), 0o644); err != nil {
+		t.Fatalf("error seeding file: %v", err)
+	}
+
+	s, err := New(root)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	args := map[string]interface{}{
+		"path": "file.txt",
+		"edits": []interface{}{
+			map[string]interface{}{"start_line": float64(1), "end_line": float64(2), "replacement": "a"},
+			map[string]interface{}{"start_line": float64(2), "end_line": float64(3), "replacement": "b"},
+		},
+	}
+
+	if _, err := s.modifyFile(args); err == nil {
+		t.Fatal("modifyFile with overlapping edits succeeded, want error")
+	}
+}
+
+func TestModifyFileAppliesNonOverlappingEditsOutOfOrder(t *testing.T) {
+	root := t.TempDir()
+	path := filepath.Join(root, "file.txt")
+	if err := os.WriteFile(path, []byte("one\ntwo\nthree\nfour\n"), 0o644); err != nil {
+		t.Fatalf("error seeding file: %v", err)
+	}
+
+	s, err := New(root)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	// Edits are given out of order (line 1 before line 3) to verify
+	// modifyFile sorts before applying rather than relying on caller order.
+	args := map[string]interface{}{
+		"path": "file.txt",
+		"edits": []interface{}{
+			map[string]interface{}{"start_line": float64(1), "end_line": float64(1), "replacement": "ONE"},
+			map[string]interface{}{"start_line": float64(3), "end_line": float64(3), "replacement": "THREE"},
+		},
+	}
+
+	if _, err := s.modifyFile(args); err != nil {
+		t.Fatalf("modifyFile: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("error reading result: %v", err)
+	}
+	want := "ONE\ntwo\nTHREE\nfour\n"
+	if string(data) != want {
+		t.Errorf("modifyFile result = %q, want %q", string(data), want)
+	}
+}
@@ -0,0 +1,102 @@
+// Package history defines the on-the-wire and on-disk shape of a
+// conversation turn, independent of any particular model provider.
+package history
+
+import (
+	"encoding/json"
+	"strings"
+
+	"mcphost-server/pkg/llm"
+)
+
+// HistoryMessage is one turn of a conversation: a role plus the
+// content blocks (text, tool_use, tool_result) that make it up.
+type HistoryMessage struct {
+	Role    string         `json:"role"`
+	Content []ContentBlock `json:"content"`
+
+	// Token usage for the CreateMessage call that produced this
+	// message, populated after the call returns.
+	InputTokens  int `json:"input_tokens,omitempty"`
+	OutputTokens int `json:"output_tokens,omitempty"`
+}
+
+// GetRole implements llm.Message.
+func (m *HistoryMessage) GetRole() string { return m.Role }
+
+// GetContent implements llm.Message, concatenating every text block in
+// the message (tool_use/tool_result blocks carry no plain text).
+func (m *HistoryMessage) GetContent() string {
+	var text strings.Builder
+	for _, block := range m.Content {
+		if block.Type == "text" {
+			text.WriteString(block.Text)
+		}
+	}
+	return text.String()
+}
+
+// GetToolCalls implements llm.Message.
+func (m *HistoryMessage) GetToolCalls() []llm.ToolCall {
+	var calls []llm.ToolCall
+	for i := range m.Content {
+		if m.Content[i].Type == "tool_use" {
+			calls = append(calls, &m.Content[i])
+		}
+	}
+	return calls
+}
+
+// GetToolResults implements llm.Message.
+func (m *HistoryMessage) GetToolResults() []llm.ToolResult {
+	var results []llm.ToolResult
+	for i := range m.Content {
+		if m.Content[i].Type == "tool_result" {
+			results = append(results, &m.Content[i])
+		}
+	}
+	return results
+}
+
+// GetUsage implements llm.Message.
+func (m *HistoryMessage) GetUsage() (inputTokens, outputTokens int) {
+	return m.InputTokens, m.OutputTokens
+}
+
+// ContentBlock is a single piece of message content. Which fields are
+// populated depends on Type ("text", "tool_use", or "tool_result").
+type ContentBlock struct {
+	Type string `json:"type"`
+
+	// text
+	Text string `json:"text,omitempty"`
+
+	// tool_use
+	ID    string          `json:"id,omitempty"`
+	Name  string          `json:"name,omitempty"`
+	Input json.RawMessage `json:"input,omitempty"`
+
+	// tool_result
+	ToolUseID string      `json:"tool_use_id,omitempty"`
+	Content   interface{} `json:"content,omitempty"`
+}
+
+// GetID implements llm.ToolCall.
+func (b *ContentBlock) GetID() string { return b.ID }
+
+// GetName implements llm.ToolCall.
+func (b *ContentBlock) GetName() string { return b.Name }
+
+// GetArguments implements llm.ToolCall, unmarshaling the raw tool_use
+// input into a map for a provider to re-encode however it needs.
+func (b *ContentBlock) GetArguments() map[string]interface{} {
+	var args map[string]interface{}
+	json.Unmarshal(b.Input, &args)
+	return args
+}
+
+// GetToolUseID implements llm.ToolResult.
+func (b *ContentBlock) GetToolUseID() string { return b.ToolUseID }
+
+// GetContent implements llm.ToolResult.
+func (b *ContentBlock) GetContent() interface{} { return b.Content }
@@ -0,0 +1,415 @@
+// Package anthropic implements llm.Provider against the Anthropic
+// Messages API using only the standard library.
+package anthropic
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"mcphost-server/pkg/llm"
+)
+
+const (
+	defaultBaseURL   = "https://api.anthropic.com"
+	apiVersion       = "2023-06-01"
+	defaultMaxTokens = 4096
+)
+
+// Provider talks to the Anthropic Messages API for a single model.
+type Provider struct {
+	apiKey  string
+	baseURL string
+	model   string
+	client  *http.Client
+}
+
+// NewProvider returns a Provider for model, using baseURL (or the
+// public Anthropic API if empty) and apiKey for authentication.
+func NewProvider(apiKey, baseURL, model string) *Provider {
+	if baseURL == "" {
+		baseURL = defaultBaseURL
+	}
+	return &Provider{apiKey: apiKey, baseURL: baseURL, model: model, client: http.DefaultClient}
+}
+
+func (p *Provider) Name() string { return "anthropic" }
+
+type apiContentBlock struct {
+	Type string `json:"type"`
+
+	Text string `json:"text,omitempty"`
+
+	ID    string          `json:"id,omitempty"`
+	Name  string          `json:"name,omitempty"`
+	Input json.RawMessage `json:"input,omitempty"`
+
+	ToolUseID string      `json:"tool_use_id,omitempty"`
+	Content   interface{} `json:"content,omitempty"`
+}
+
+type apiMessage struct {
+	Role    string            `json:"role"`
+	Content []apiContentBlock `json:"content"`
+}
+
+type apiSchema struct {
+	Type       string                 `json:"type"`
+	Properties map[string]interface{} `json:"properties,omitempty"`
+	Required   []string               `json:"required,omitempty"`
+}
+
+type apiTool struct {
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	InputSchema apiSchema `json:"input_schema"`
+}
+
+type apiRequest struct {
+	Model     string       `json:"model"`
+	System    string       `json:"system,omitempty"`
+	Messages  []apiMessage `json:"messages"`
+	Tools     []apiTool    `json:"tools,omitempty"`
+	MaxTokens int          `json:"max_tokens"`
+	Stream    bool         `json:"stream,omitempty"`
+}
+
+type apiUsage struct {
+	InputTokens  int `json:"input_tokens"`
+	OutputTokens int `json:"output_tokens"`
+}
+
+type apiResponse struct {
+	Role    string            `json:"role"`
+	Content []apiContentBlock `json:"content"`
+	Usage   apiUsage          `json:"usage"`
+}
+
+// toAPIMessages converts a history of llm.Message into Anthropic's wire
+// shape. prompt is not re-appended here: by the time a Provider is
+// called, the caller (server.runPrompt/streamPrompt) has already added
+// it as the last message in messages.
+func toAPIMessages(messages []llm.Message) []apiMessage {
+	apiMessages := make([]apiMessage, len(messages))
+	for i, m := range messages {
+		apiMessages[i] = apiMessage{Role: m.GetRole(), Content: toAPIContent(m)}
+	}
+	return apiMessages
+}
+
+func toAPIContent(m llm.Message) []apiContentBlock {
+	var blocks []apiContentBlock
+
+	if text := m.GetContent(); text != "" {
+		blocks = append(blocks, apiContentBlock{Type: "text", Text: text})
+	}
+
+	for _, call := range m.GetToolCalls() {
+		input, _ := json.Marshal(call.GetArguments())
+		blocks = append(blocks, apiContentBlock{
+			Type:  "tool_use",
+			ID:    call.GetID(),
+			Name:  call.GetName(),
+			Input: input,
+		})
+	}
+
+	for _, result := range m.GetToolResults() {
+		blocks = append(blocks, apiContentBlock{
+			Type:      "tool_result",
+			ToolUseID: result.GetToolUseID(),
+			Content:   result.GetContent(),
+		})
+	}
+
+	return blocks
+}
+
+func toAPITools(tools []llm.Tool) []apiTool {
+	apiTools := make([]apiTool, len(tools))
+	for i, tool := range tools {
+		apiTools[i] = apiTool{
+			Name:        tool.Name,
+			Description: tool.Description,
+			InputSchema: apiSchema{
+				Type:       tool.InputSchema.Type,
+				Properties: tool.InputSchema.Properties,
+				Required:   tool.InputSchema.Required,
+			},
+		}
+	}
+	return apiTools
+}
+
+func (p *Provider) buildRequest(systemPrompt string, messages []llm.Message, tools []llm.Tool, stream bool) apiRequest {
+	return apiRequest{
+		Model:     p.model,
+		System:    systemPrompt,
+		Messages:  toAPIMessages(messages),
+		Tools:     toAPITools(tools),
+		MaxTokens: defaultMaxTokens,
+		Stream:    stream,
+	}
+}
+
+func (p *Provider) setHeaders(req *http.Request) {
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("x-api-key", p.apiKey)
+	req.Header.Set("anthropic-version", apiVersion)
+}
+
+func (p *Provider) do(ctx context.Context, reqBody apiRequest) (*http.Response, error) {
+	data, err := json.Marshal(reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.baseURL+"/v1/messages", bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("error building request: %w", err)
+	}
+	p.setHeaders(req)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error calling anthropic: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("anthropic API error (%d): %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return resp, nil
+}
+
+// CreateMessage implements llm.Provider.
+func (p *Provider) CreateMessage(ctx context.Context, prompt, systemPrompt string, messages []llm.Message, tools []llm.Tool) (llm.Message, error) {
+	resp, err := p.do(ctx, p.buildRequest(systemPrompt, messages, tools, false))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var apiResp apiResponse
+	if err := json.NewDecoder(resp.Body).Decode(&apiResp); err != nil {
+		return nil, fmt.Errorf("error decoding response: %w", err)
+	}
+
+	return &message{role: apiResp.Role, content: apiResp.Content, usage: apiResp.Usage}, nil
+}
+
+// message wraps an apiResponse's content blocks to implement llm.Message.
+type message struct {
+	role    string
+	content []apiContentBlock
+	usage   apiUsage
+}
+
+func (m *message) GetRole() string { return m.role }
+
+func (m *message) GetContent() string {
+	var text strings.Builder
+	for _, block := range m.content {
+		if block.Type == "text" {
+			text.WriteString(block.Text)
+		}
+	}
+	return text.String()
+}
+
+func (m *message) GetToolCalls() []llm.ToolCall {
+	var calls []llm.ToolCall
+	for _, block := range m.content {
+		if block.Type == "tool_use" {
+			calls = append(calls, &toolCall{id: block.ID, name: block.Name, input: block.Input})
+		}
+	}
+	return calls
+}
+
+func (m *message) GetToolResults() []llm.ToolResult { return nil }
+
+func (m *message) GetUsage() (int, int) { return m.usage.InputTokens, m.usage.OutputTokens }
+
+type toolCall struct {
+	id, name string
+	input    json.RawMessage
+}
+
+func (t *toolCall) GetID() string   { return t.id }
+func (t *toolCall) GetName() string { return t.name }
+func (t *toolCall) GetArguments() map[string]interface{} {
+	var args map[string]interface{}
+	json.Unmarshal(t.input, &args)
+	return args
+}
+
+// StreamMessage implements llm.Provider using Anthropic's native SSE
+// streaming format (message_start/content_block_start/_delta/_stop/
+// message_delta/message_stop).
+func (p *Provider) StreamMessage(ctx context.Context, prompt, systemPrompt string, messages []llm.Message, tools []llm.Tool) (<-chan llm.Event, error) {
+	resp, err := p.do(ctx, p.buildRequest(systemPrompt, messages, tools, true))
+	if err != nil {
+		return nil, err
+	}
+
+	events := make(chan llm.Event)
+	go streamEvents(resp.Body, events)
+	return events, nil
+}
+
+type sseContentBlock struct {
+	Type string `json:"type"`
+	ID   string `json:"id,omitempty"`
+	Name string `json:"name,omitempty"`
+}
+
+type sseDelta struct {
+	Type        string `json:"type"`
+	Text        string `json:"text,omitempty"`
+	PartialJSON string `json:"partial_json,omitempty"`
+}
+
+type sseMessageStart struct {
+	Message struct {
+		Usage apiUsage `json:"usage"`
+	} `json:"message"`
+}
+
+type sseContentBlockStart struct {
+	Index        int             `json:"index"`
+	ContentBlock sseContentBlock `json:"content_block"`
+}
+
+type sseContentBlockDelta struct {
+	Index int      `json:"index"`
+	Delta sseDelta `json:"delta"`
+}
+
+type sseContentBlockStop struct {
+	Index int `json:"index"`
+}
+
+type sseMessageDelta struct {
+	Usage struct {
+		OutputTokens int `json:"output_tokens"`
+	} `json:"usage"`
+}
+
+type sseError struct {
+	Error struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// blockState accumulates a single content block's streamed pieces -
+// the text seen so far for a text block, or the concatenated
+// partial_json fragments for a tool_use block's input.
+type blockState struct {
+	kind     string
+	id, name string
+	json     strings.Builder
+}
+
+// streamEvents reads an Anthropic SSE response body and translates it
+// into llm.Events, closing events when the stream ends.
+func streamEvents(body io.ReadCloser, events chan<- llm.Event) {
+	defer close(events)
+	defer body.Close()
+
+	blocks := make(map[int]*blockState)
+	var inputTokens, outputTokens int
+
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	var eventName string
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "event:"):
+			eventName = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+
+		case strings.HasPrefix(line, "data:"):
+			data := strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+
+			switch eventName {
+			case "message_start":
+				var start sseMessageStart
+				if json.Unmarshal([]byte(data), &start) == nil {
+					inputTokens = start.Message.Usage.InputTokens
+				}
+
+			case "content_block_start":
+				var start sseContentBlockStart
+				if json.Unmarshal([]byte(data), &start) == nil {
+					blocks[start.Index] = &blockState{
+						kind: start.ContentBlock.Type,
+						id:   start.ContentBlock.ID,
+						name: start.ContentBlock.Name,
+					}
+				}
+
+			case "content_block_delta":
+				var delta sseContentBlockDelta
+				if json.Unmarshal([]byte(data), &delta) != nil {
+					continue
+				}
+				block, ok := blocks[delta.Index]
+				if !ok {
+					continue
+				}
+				switch delta.Delta.Type {
+				case "text_delta":
+					events <- llm.Event{Type: llm.EventTextDelta, Text: delta.Delta.Text}
+				case "input_json_delta":
+					block.json.WriteString(delta.Delta.PartialJSON)
+				}
+
+			case "content_block_stop":
+				var stop sseContentBlockStop
+				if json.Unmarshal([]byte(data), &stop) != nil {
+					continue
+				}
+				block, ok := blocks[stop.Index]
+				if !ok || block.kind != "tool_use" {
+					continue
+				}
+				input := block.json.String()
+				if input == "" {
+					input = "{}"
+				}
+				events <- llm.Event{
+					Type:     llm.EventToolUseEnd,
+					ToolID:   block.id,
+					ToolName: block.name,
+					Input:    json.RawMessage(input),
+				}
+
+			case "message_delta":
+				var delta sseMessageDelta
+				if json.Unmarshal([]byte(data), &delta) == nil {
+					outputTokens = delta.Usage.OutputTokens
+				}
+
+			case "error":
+				var apiErr sseError
+				json.Unmarshal([]byte(data), &apiErr)
+				events <- llm.Event{Type: llm.EventError, Err: apiErr.Error.Message}
+				return
+			}
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		events <- llm.Event{Type: llm.EventError, Err: err.Error()}
+		return
+	}
+
+	events <- llm.Event{Type: llm.EventUsage, InputTokens: inputTokens, OutputTokens: outputTokens}
+}
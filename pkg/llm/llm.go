@@ -0,0 +1,101 @@
+// Package llm defines the provider-agnostic types the server talks to
+// a model through. It has no dependency on pkg/history so the two
+// packages can depend on each other in one direction only: history
+// implements these interfaces rather than llm importing history's
+// concrete types.
+package llm
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// Schema is a JSON-schema-shaped description of a tool's input, mirroring
+// mcp.ToolInputSchema closely enough to convert between the two directly.
+type Schema struct {
+	Type       string                 `json:"type"`
+	Properties map[string]interface{} `json:"properties,omitempty"`
+	Required   []string               `json:"required,omitempty"`
+}
+
+// Tool is a single callable tool offered to a model, namespaced as
+// "serverName__toolName" by the caller before it reaches here.
+type Tool struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	InputSchema Schema `json:"input_schema"`
+}
+
+// ToolCall is a tool invocation a model emitted as part of a response.
+type ToolCall interface {
+	GetID() string
+	GetName() string
+	GetArguments() map[string]interface{}
+}
+
+// ToolResult is the outcome of a tool call, fed back to the model as
+// part of a later message in the conversation.
+type ToolResult interface {
+	GetToolUseID() string
+	GetContent() interface{}
+}
+
+// Message is one turn of a conversation, in whatever shape a provider
+// needs it in. pkg/history's HistoryMessage and ContentBlock implement
+// this (and ToolCall/ToolResult) so the existing message history can be
+// handed to a Provider without copying it into provider-owned types.
+type Message interface {
+	GetRole() string
+	GetContent() string
+	GetToolCalls() []ToolCall
+	GetToolResults() []ToolResult
+	GetUsage() (inputTokens, outputTokens int)
+}
+
+// Provider is a model backend capable of turning a message history plus
+// an offered toolset into the model's next turn, either all at once
+// (CreateMessage) or incrementally as it's generated (StreamMessage).
+type Provider interface {
+	Name() string
+	CreateMessage(ctx context.Context, prompt, systemPrompt string, messages []Message, tools []Tool) (Message, error)
+	StreamMessage(ctx context.Context, prompt, systemPrompt string, messages []Message, tools []Tool) (<-chan Event, error)
+}
+
+// EventType identifies what kind of update an Event carries. It's a
+// string (rather than an int) because it's written directly into SSE
+// frames as the "event:" field.
+type EventType string
+
+const (
+	EventTextDelta    EventType = "text_delta"
+	EventToolUseStart EventType = "tool_use_start"
+	EventToolUseEnd   EventType = "tool_use_end"
+	EventToolResult   EventType = "tool_result"
+	EventUsage        EventType = "usage"
+	EventError        EventType = "error"
+	EventDone         EventType = "done"
+)
+
+// Event is one update in a StreamMessage channel. Which fields are
+// populated depends on Type.
+type Event struct {
+	Type EventType `json:"type"`
+
+	// text_delta
+	Text string `json:"text,omitempty"`
+
+	// tool_use_start, tool_use_end
+	ToolID   string          `json:"tool_id,omitempty"`
+	ToolName string          `json:"tool_name,omitempty"`
+	Input    json.RawMessage `json:"input,omitempty"`
+
+	// tool_result
+	Result interface{} `json:"result,omitempty"`
+
+	// error
+	Err string `json:"error,omitempty"`
+
+	// usage
+	InputTokens  int `json:"input_tokens,omitempty"`
+	OutputTokens int `json:"output_tokens,omitempty"`
+}
@@ -0,0 +1,301 @@
+// Package ollama implements llm.Provider against a local Ollama chat
+// API using only the standard library.
+package ollama
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"mcphost-server/pkg/llm"
+)
+
+const defaultHost = "http://localhost:11434"
+
+// Provider talks to a local Ollama server for a single model.
+type Provider struct {
+	host   string
+	model  string
+	client *http.Client
+}
+
+// NewProvider returns a Provider for model, reading the server address
+// from OLLAMA_HOST (defaulting to the standard local port).
+func NewProvider(model string) (*Provider, error) {
+	if model == "" {
+		return nil, fmt.Errorf("ollama model is required")
+	}
+
+	host := os.Getenv("OLLAMA_HOST")
+	if host == "" {
+		host = defaultHost
+	}
+	return &Provider{host: host, model: model, client: http.DefaultClient}, nil
+}
+
+func (p *Provider) Name() string { return "ollama" }
+
+type apiFunctionCall struct {
+	Name      string                 `json:"name"`
+	Arguments map[string]interface{} `json:"arguments"`
+}
+
+type apiToolCall struct {
+	Function apiFunctionCall `json:"function"`
+}
+
+type apiMessage struct {
+	Role      string        `json:"role"`
+	Content   string        `json:"content,omitempty"`
+	ToolCalls []apiToolCall `json:"tool_calls,omitempty"`
+}
+
+type apiSchema struct {
+	Type       string                 `json:"type"`
+	Properties map[string]interface{} `json:"properties,omitempty"`
+	Required   []string               `json:"required,omitempty"`
+}
+
+type apiFunction struct {
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	Parameters  apiSchema `json:"parameters"`
+}
+
+type apiTool struct {
+	Type     string      `json:"type"`
+	Function apiFunction `json:"function"`
+}
+
+type apiRequest struct {
+	Model    string       `json:"model"`
+	Messages []apiMessage `json:"messages"`
+	Tools    []apiTool    `json:"tools,omitempty"`
+	Stream   bool         `json:"stream"`
+}
+
+type apiResponse struct {
+	Message         apiMessage `json:"message"`
+	Done            bool       `json:"done"`
+	PromptEvalCount int        `json:"prompt_eval_count"`
+	EvalCount       int        `json:"eval_count"`
+}
+
+// toAPIMessages converts a history of llm.Message into Ollama's wire
+// shape. A message's tool_result blocks, if any, become separate
+// role:"tool" messages, matching how the conversation is turned into
+// the OpenAI-style chat format Ollama's /api/chat expects.
+func toAPIMessages(messages []llm.Message) []apiMessage {
+	var apiMessages []apiMessage
+	for _, m := range messages {
+		if results := m.GetToolResults(); len(results) > 0 {
+			for _, result := range results {
+				content, _ := json.Marshal(result.GetContent())
+				apiMessages = append(apiMessages, apiMessage{Role: "tool", Content: string(content)})
+			}
+			continue
+		}
+
+		var toolCalls []apiToolCall
+		for _, call := range m.GetToolCalls() {
+			toolCalls = append(toolCalls, apiToolCall{
+				Function: apiFunctionCall{Name: call.GetName(), Arguments: call.GetArguments()},
+			})
+		}
+
+		apiMessages = append(apiMessages, apiMessage{
+			Role:      m.GetRole(),
+			Content:   m.GetContent(),
+			ToolCalls: toolCalls,
+		})
+	}
+	return apiMessages
+}
+
+func toAPITools(tools []llm.Tool) []apiTool {
+	apiTools := make([]apiTool, len(tools))
+	for i, tool := range tools {
+		apiTools[i] = apiTool{
+			Type: "function",
+			Function: apiFunction{
+				Name:        tool.Name,
+				Description: tool.Description,
+				Parameters: apiSchema{
+					Type:       tool.InputSchema.Type,
+					Properties: tool.InputSchema.Properties,
+					Required:   tool.InputSchema.Required,
+				},
+			},
+		}
+	}
+	return apiTools
+}
+
+func (p *Provider) buildRequest(messages []llm.Message, tools []llm.Tool, stream bool) apiRequest {
+	return apiRequest{
+		Model:    p.model,
+		Messages: toAPIMessages(messages),
+		Tools:    toAPITools(tools),
+		Stream:   stream,
+	}
+}
+
+func (p *Provider) do(ctx context.Context, reqBody apiRequest) (*http.Response, error) {
+	data, err := json.Marshal(reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.host+"/api/chat", bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("error building request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error calling ollama: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("ollama API error (%d): %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return resp, nil
+}
+
+// CreateMessage implements llm.Provider. systemPrompt, if set, is sent
+// as a leading role:"system" message.
+func (p *Provider) CreateMessage(ctx context.Context, prompt, systemPrompt string, messages []llm.Message, tools []llm.Tool) (llm.Message, error) {
+	wireMessages := toAPIMessages(messages)
+	if systemPrompt != "" {
+		wireMessages = append([]apiMessage{{Role: "system", Content: systemPrompt}}, wireMessages...)
+	}
+
+	resp, err := p.do(ctx, apiRequest{Model: p.model, Messages: wireMessages, Tools: toAPITools(tools), Stream: false})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var apiResp apiResponse
+	if err := json.NewDecoder(resp.Body).Decode(&apiResp); err != nil {
+		return nil, fmt.Errorf("error decoding response: %w", err)
+	}
+
+	return &message{
+		role:         apiResp.Message.Role,
+		content:      apiResp.Message.Content,
+		toolCalls:    apiResp.Message.ToolCalls,
+		promptTokens: apiResp.PromptEvalCount,
+		evalTokens:   apiResp.EvalCount,
+	}, nil
+}
+
+// message wraps an Ollama chat response to implement llm.Message.
+type message struct {
+	role                     string
+	content                  string
+	toolCalls                []apiToolCall
+	promptTokens, evalTokens int
+}
+
+func (m *message) GetRole() string    { return m.role }
+func (m *message) GetContent() string { return m.content }
+
+func (m *message) GetToolCalls() []llm.ToolCall {
+	var calls []llm.ToolCall
+	for i, call := range m.toolCalls {
+		// Ollama doesn't assign tool calls an ID, so synthesize one from
+		// its position in the response - stable within a single turn,
+		// which is all a tool_use/tool_result pairing needs.
+		calls = append(calls, &toolCall{id: fmt.Sprintf("call_%d", i), name: call.Function.Name, arguments: call.Function.Arguments})
+	}
+	return calls
+}
+
+func (m *message) GetToolResults() []llm.ToolResult { return nil }
+
+func (m *message) GetUsage() (int, int) { return m.promptTokens, m.evalTokens }
+
+type toolCall struct {
+	id, name  string
+	arguments map[string]interface{}
+}
+
+func (t *toolCall) GetID() string                        { return t.id }
+func (t *toolCall) GetName() string                      { return t.name }
+func (t *toolCall) GetArguments() map[string]interface{} { return t.arguments }
+
+// StreamMessage implements llm.Provider. Ollama's /api/chat streams
+// newline-delimited JSON objects rather than SSE; the final one carries
+// done:true plus the prompt/eval token counts.
+func (p *Provider) StreamMessage(ctx context.Context, prompt, systemPrompt string, messages []llm.Message, tools []llm.Tool) (<-chan llm.Event, error) {
+	wireMessages := toAPIMessages(messages)
+	if systemPrompt != "" {
+		wireMessages = append([]apiMessage{{Role: "system", Content: systemPrompt}}, wireMessages...)
+	}
+
+	resp, err := p.do(ctx, apiRequest{Model: p.model, Messages: wireMessages, Tools: toAPITools(tools), Stream: true})
+	if err != nil {
+		return nil, err
+	}
+
+	events := make(chan llm.Event)
+	go streamEvents(resp.Body, events)
+	return events, nil
+}
+
+func streamEvents(body io.ReadCloser, events chan<- llm.Event) {
+	defer close(events)
+	defer body.Close()
+
+	var promptTokens, evalTokens int
+	var nextCallIndex int
+
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		var chunk apiResponse
+		if err := json.Unmarshal(line, &chunk); err != nil {
+			events <- llm.Event{Type: llm.EventError, Err: err.Error()}
+			return
+		}
+
+		if chunk.Message.Content != "" {
+			events <- llm.Event{Type: llm.EventTextDelta, Text: chunk.Message.Content}
+		}
+		for _, call := range chunk.Message.ToolCalls {
+			input, _ := json.Marshal(call.Function.Arguments)
+			events <- llm.Event{
+				Type:     llm.EventToolUseEnd,
+				ToolID:   fmt.Sprintf("call_%d", nextCallIndex),
+				ToolName: call.Function.Name,
+				Input:    input,
+			}
+			nextCallIndex++
+		}
+		if chunk.Done {
+			promptTokens = chunk.PromptEvalCount
+			evalTokens = chunk.EvalCount
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		events <- llm.Event{Type: llm.EventError, Err: err.Error()}
+		return
+	}
+
+	events <- llm.Event{Type: llm.EventUsage, InputTokens: promptTokens, OutputTokens: evalTokens}
+}
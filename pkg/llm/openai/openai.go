@@ -0,0 +1,369 @@
+// Package openai implements llm.Provider against OpenAI's Chat
+// Completions API using only the standard library.
+package openai
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"mcphost-server/pkg/llm"
+)
+
+const defaultBaseURL = "https://api.openai.com"
+
+// Provider talks to an OpenAI-compatible Chat Completions API for a
+// single model.
+type Provider struct {
+	apiKey  string
+	baseURL string
+	model   string
+	client  *http.Client
+}
+
+// NewProvider returns a Provider for model, using baseURL (or the
+// public OpenAI API if empty) and apiKey for authentication.
+func NewProvider(apiKey, baseURL, model string) *Provider {
+	if baseURL == "" {
+		baseURL = defaultBaseURL
+	}
+	return &Provider{apiKey: apiKey, baseURL: baseURL, model: model, client: http.DefaultClient}
+}
+
+func (p *Provider) Name() string { return "openai" }
+
+type apiFunctionCall struct {
+	Name      string `json:"name"`
+	Arguments string `json:"arguments"`
+}
+
+type apiToolCall struct {
+	ID       string          `json:"id,omitempty"`
+	Type     string          `json:"type"`
+	Function apiFunctionCall `json:"function"`
+}
+
+type apiMessage struct {
+	Role       string        `json:"role"`
+	Content    string        `json:"content,omitempty"`
+	ToolCalls  []apiToolCall `json:"tool_calls,omitempty"`
+	ToolCallID string        `json:"tool_call_id,omitempty"`
+}
+
+type apiSchema struct {
+	Type       string                 `json:"type"`
+	Properties map[string]interface{} `json:"properties,omitempty"`
+	Required   []string               `json:"required,omitempty"`
+}
+
+type apiFunction struct {
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	Parameters  apiSchema `json:"parameters"`
+}
+
+type apiTool struct {
+	Type     string      `json:"type"`
+	Function apiFunction `json:"function"`
+}
+
+type apiRequest struct {
+	Model         string            `json:"model"`
+	Messages      []apiMessage      `json:"messages"`
+	Tools         []apiTool         `json:"tools,omitempty"`
+	Stream        bool              `json:"stream,omitempty"`
+	StreamOptions *apiStreamOptions `json:"stream_options,omitempty"`
+}
+
+type apiStreamOptions struct {
+	IncludeUsage bool `json:"include_usage"`
+}
+
+type apiUsage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+}
+
+type apiChoice struct {
+	Message apiMessage `json:"message"`
+}
+
+type apiResponse struct {
+	Choices []apiChoice `json:"choices"`
+	Usage   apiUsage    `json:"usage"`
+}
+
+// toAPIMessages converts a history of llm.Message into OpenAI's wire
+// shape. A message's tool_result blocks, if any, become separate
+// role:"tool" messages since the Chat Completions API has no way to
+// carry more than one tool result per message.
+func toAPIMessages(systemPrompt string, messages []llm.Message) []apiMessage {
+	var apiMessages []apiMessage
+	if systemPrompt != "" {
+		apiMessages = append(apiMessages, apiMessage{Role: "system", Content: systemPrompt})
+	}
+
+	for _, m := range messages {
+		if results := m.GetToolResults(); len(results) > 0 {
+			for _, result := range results {
+				content, _ := json.Marshal(result.GetContent())
+				apiMessages = append(apiMessages, apiMessage{
+					Role:       "tool",
+					Content:    string(content),
+					ToolCallID: result.GetToolUseID(),
+				})
+			}
+			continue
+		}
+
+		var toolCalls []apiToolCall
+		for _, call := range m.GetToolCalls() {
+			args, _ := json.Marshal(call.GetArguments())
+			toolCalls = append(toolCalls, apiToolCall{
+				ID:       call.GetID(),
+				Type:     "function",
+				Function: apiFunctionCall{Name: call.GetName(), Arguments: string(args)},
+			})
+		}
+
+		apiMessages = append(apiMessages, apiMessage{
+			Role:      m.GetRole(),
+			Content:   m.GetContent(),
+			ToolCalls: toolCalls,
+		})
+	}
+	return apiMessages
+}
+
+func toAPITools(tools []llm.Tool) []apiTool {
+	apiTools := make([]apiTool, len(tools))
+	for i, tool := range tools {
+		apiTools[i] = apiTool{
+			Type: "function",
+			Function: apiFunction{
+				Name:        tool.Name,
+				Description: tool.Description,
+				Parameters: apiSchema{
+					Type:       tool.InputSchema.Type,
+					Properties: tool.InputSchema.Properties,
+					Required:   tool.InputSchema.Required,
+				},
+			},
+		}
+	}
+	return apiTools
+}
+
+func (p *Provider) buildRequest(systemPrompt string, messages []llm.Message, tools []llm.Tool, stream bool) apiRequest {
+	req := apiRequest{
+		Model:    p.model,
+		Messages: toAPIMessages(systemPrompt, messages),
+		Tools:    toAPITools(tools),
+		Stream:   stream,
+	}
+	if stream {
+		req.StreamOptions = &apiStreamOptions{IncludeUsage: true}
+	}
+	return req
+}
+
+func (p *Provider) do(ctx context.Context, reqBody apiRequest) (*http.Response, error) {
+	data, err := json.Marshal(reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.baseURL+"/v1/chat/completions", bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("error building request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+p.apiKey)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error calling openai: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("openai API error (%d): %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return resp, nil
+}
+
+// CreateMessage implements llm.Provider.
+func (p *Provider) CreateMessage(ctx context.Context, prompt, systemPrompt string, messages []llm.Message, tools []llm.Tool) (llm.Message, error) {
+	resp, err := p.do(ctx, p.buildRequest(systemPrompt, messages, tools, false))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var apiResp apiResponse
+	if err := json.NewDecoder(resp.Body).Decode(&apiResp); err != nil {
+		return nil, fmt.Errorf("error decoding response: %w", err)
+	}
+	if len(apiResp.Choices) == 0 {
+		return nil, fmt.Errorf("openai response had no choices")
+	}
+
+	choice := apiResp.Choices[0].Message
+	return &message{role: choice.Role, content: choice.Content, toolCalls: choice.ToolCalls, usage: apiResp.Usage}, nil
+}
+
+// message wraps a chat completion choice to implement llm.Message.
+type message struct {
+	role      string
+	content   string
+	toolCalls []apiToolCall
+	usage     apiUsage
+}
+
+func (m *message) GetRole() string    { return m.role }
+func (m *message) GetContent() string { return m.content }
+
+func (m *message) GetToolCalls() []llm.ToolCall {
+	var calls []llm.ToolCall
+	for _, call := range m.toolCalls {
+		calls = append(calls, &toolCall{id: call.ID, name: call.Function.Name, arguments: call.Function.Arguments})
+	}
+	return calls
+}
+
+func (m *message) GetToolResults() []llm.ToolResult { return nil }
+
+func (m *message) GetUsage() (int, int) { return m.usage.PromptTokens, m.usage.CompletionTokens }
+
+type toolCall struct {
+	id, name, arguments string
+}
+
+func (t *toolCall) GetID() string   { return t.id }
+func (t *toolCall) GetName() string { return t.name }
+func (t *toolCall) GetArguments() map[string]interface{} {
+	var args map[string]interface{}
+	json.Unmarshal([]byte(t.arguments), &args)
+	return args
+}
+
+// StreamMessage implements llm.Provider using the Chat Completions
+// streaming format: a run of "data: {...}" chunks carrying incremental
+// deltas, terminated by "data: [DONE]".
+func (p *Provider) StreamMessage(ctx context.Context, prompt, systemPrompt string, messages []llm.Message, tools []llm.Tool) (<-chan llm.Event, error) {
+	resp, err := p.do(ctx, p.buildRequest(systemPrompt, messages, tools, true))
+	if err != nil {
+		return nil, err
+	}
+
+	events := make(chan llm.Event)
+	go streamEvents(resp.Body, events)
+	return events, nil
+}
+
+type apiStreamToolCall struct {
+	Index    int    `json:"index"`
+	ID       string `json:"id,omitempty"`
+	Function struct {
+		Name      string `json:"name,omitempty"`
+		Arguments string `json:"arguments,omitempty"`
+	} `json:"function"`
+}
+
+type apiStreamDelta struct {
+	Content   string              `json:"content,omitempty"`
+	ToolCalls []apiStreamToolCall `json:"tool_calls,omitempty"`
+}
+
+type apiStreamChoice struct {
+	Delta apiStreamDelta `json:"delta"`
+}
+
+type apiStreamChunk struct {
+	Choices []apiStreamChoice `json:"choices"`
+	Usage   *apiUsage         `json:"usage,omitempty"`
+}
+
+// callState accumulates one tool call's streamed pieces - the id/name
+// arrive in the first chunk that mentions its index, the arguments
+// arrive as a run of partial JSON string fragments across chunks.
+type callState struct {
+	id, name string
+	args     strings.Builder
+}
+
+func streamEvents(body io.ReadCloser, events chan<- llm.Event) {
+	defer close(events)
+	defer body.Close()
+
+	calls := make(map[int]*callState)
+	var order []int
+	var inputTokens, outputTokens int
+
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "data:") {
+			continue
+		}
+		data := strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+		if data == "[DONE]" {
+			break
+		}
+
+		var chunk apiStreamChunk
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			continue
+		}
+		if chunk.Usage != nil {
+			inputTokens = chunk.Usage.PromptTokens
+			outputTokens = chunk.Usage.CompletionTokens
+		}
+		if len(chunk.Choices) == 0 {
+			continue
+		}
+
+		delta := chunk.Choices[0].Delta
+		if delta.Content != "" {
+			events <- llm.Event{Type: llm.EventTextDelta, Text: delta.Content}
+		}
+		for _, tc := range delta.ToolCalls {
+			state, ok := calls[tc.Index]
+			if !ok {
+				state = &callState{}
+				calls[tc.Index] = state
+				order = append(order, tc.Index)
+			}
+			if tc.ID != "" {
+				state.id = tc.ID
+			}
+			if tc.Function.Name != "" {
+				state.name = tc.Function.Name
+			}
+			state.args.WriteString(tc.Function.Arguments)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		events <- llm.Event{Type: llm.EventError, Err: err.Error()}
+		return
+	}
+
+	for _, idx := range order {
+		state := calls[idx]
+		input := state.args.String()
+		if input == "" {
+			input = "{}"
+		}
+		events <- llm.Event{Type: llm.EventToolUseEnd, ToolID: state.id, ToolName: state.name, Input: json.RawMessage(input)}
+	}
+
+	events <- llm.Event{Type: llm.EventUsage, InputTokens: inputTokens, OutputTokens: outputTokens}
+}
@@ -0,0 +1,331 @@
+// Package sessions persists conversations as a DAG of messages so a
+// conversation can branch whenever an earlier message is edited,
+// instead of living only in a single global in-memory slice.
+package sessions
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"mcphost-server/pkg/history"
+
+	_ "modernc.org/sqlite"
+)
+
+// Session is one conversation thread. HeadID points at the message
+// currently at the tip of the branch new prompts are appended to.
+type Session struct {
+	ID        string    `json:"id"`
+	Model     string    `json:"model"`
+	Agent     string    `json:"agent,omitempty"`
+	HeadID    string    `json:"head_id,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Message is a single node in a session's message DAG. ParentID is
+// empty for the first message in a branch.
+type Message struct {
+	ID           string                  `json:"id"`
+	SessionID    string                  `json:"session_id"`
+	ParentID     string                  `json:"parent_id,omitempty"`
+	Role         string                  `json:"role"`
+	Content      []history.ContentBlock `json:"content"`
+	InputTokens  int                     `json:"input_tokens,omitempty"`
+	OutputTokens int                     `json:"output_tokens,omitempty"`
+	CreatedAt    time.Time               `json:"created_at"`
+}
+
+// Store is a SQLite-backed session store. modernc.org/sqlite is used
+// instead of mattn/go-sqlite3 to keep the binary CGo-free.
+type Store struct {
+	db *sql.DB
+}
+
+// Open opens (creating if necessary) the SQLite database at path and
+// ensures the sessions/messages schema exists.
+func Open(path string) (*Store, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("error opening session store: %w", err)
+	}
+
+	store := &Store{db: db}
+	if err := store.migrate(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return store, nil
+}
+
+func (s *Store) migrate() error {
+	_, err := s.db.Exec(`
+		CREATE TABLE IF NOT EXISTS sessions (
+			id         TEXT PRIMARY KEY,
+			model      TEXT NOT NULL,
+			agent      TEXT,
+			head_id    TEXT,
+			created_at TEXT NOT NULL,
+			updated_at TEXT NOT NULL
+		);
+		CREATE TABLE IF NOT EXISTS messages (
+			id            TEXT PRIMARY KEY,
+			session_id    TEXT NOT NULL,
+			parent_id     TEXT,
+			role          TEXT NOT NULL,
+			content       TEXT NOT NULL,
+			input_tokens  INTEGER NOT NULL DEFAULT 0,
+			output_tokens INTEGER NOT NULL DEFAULT 0,
+			created_at TEXT NOT NULL
+		);
+		CREATE INDEX IF NOT EXISTS messages_session_idx ON messages(session_id);
+		CREATE TABLE IF NOT EXISTS usage (
+			id            INTEGER PRIMARY KEY AUTOINCREMENT,
+			session_id    TEXT NOT NULL,
+			model         TEXT NOT NULL,
+			input_tokens  INTEGER NOT NULL,
+			output_tokens INTEGER NOT NULL,
+			created_at TEXT NOT NULL
+		);
+		CREATE INDEX IF NOT EXISTS usage_session_idx ON usage(session_id);
+	`)
+	if err != nil {
+		return fmt.Errorf("error migrating session store: %w", err)
+	}
+	return nil
+}
+
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// CreateSession starts a new, empty conversation.
+func (s *Store) CreateSession(id, model, agent string) (*Session, error) {
+	now := time.Now().UTC()
+	session := &Session{ID: id, Model: model, Agent: agent, CreatedAt: now, UpdatedAt: now}
+
+	_, err := s.db.Exec(
+		`INSERT INTO sessions (id, model, agent, head_id, created_at, updated_at) VALUES (?, ?, ?, '', ?, ?)`,
+		session.ID, session.Model, session.Agent, now.Format(time.RFC3339Nano), now.Format(time.RFC3339Nano),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error creating session: %w", err)
+	}
+	return session, nil
+}
+
+// ListSessions returns every session, most recently updated first.
+func (s *Store) ListSessions() ([]*Session, error) {
+	rows, err := s.db.Query(`SELECT id, model, agent, head_id, created_at, updated_at FROM sessions ORDER BY updated_at DESC`)
+	if err != nil {
+		return nil, fmt.Errorf("error listing sessions: %w", err)
+	}
+	defer rows.Close()
+
+	var result []*Session
+	for rows.Next() {
+		var session Session
+		var createdAt, updatedAt string
+		if err := rows.Scan(&session.ID, &session.Model, &session.Agent, &session.HeadID, &createdAt, &updatedAt); err != nil {
+			return nil, fmt.Errorf("error scanning session: %w", err)
+		}
+		session.CreatedAt, _ = time.Parse(time.RFC3339Nano, createdAt)
+		session.UpdatedAt, _ = time.Parse(time.RFC3339Nano, updatedAt)
+		result = append(result, &session)
+	}
+	return result, rows.Err()
+}
+
+// GetSession loads a single session by ID.
+func (s *Store) GetSession(id string) (*Session, error) {
+	var session Session
+	var createdAt, updatedAt string
+	err := s.db.QueryRow(
+		`SELECT id, model, agent, head_id, created_at, updated_at FROM sessions WHERE id = ?`, id,
+	).Scan(&session.ID, &session.Model, &session.Agent, &session.HeadID, &createdAt, &updatedAt)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("session not found: %s", id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error loading session: %w", err)
+	}
+	session.CreatedAt, _ = time.Parse(time.RFC3339Nano, createdAt)
+	session.UpdatedAt, _ = time.Parse(time.RFC3339Nano, updatedAt)
+	return &session, nil
+}
+
+// DeleteSession removes a session and every message and usage record
+// tied to it.
+func (s *Store) DeleteSession(id string) error {
+	if _, err := s.db.Exec(`DELETE FROM messages WHERE session_id = ?`, id); err != nil {
+		return fmt.Errorf("error deleting session messages: %w", err)
+	}
+	if _, err := s.db.Exec(`DELETE FROM usage WHERE session_id = ?`, id); err != nil {
+		return fmt.Errorf("error deleting session usage: %w", err)
+	}
+	if _, err := s.db.Exec(`DELETE FROM sessions WHERE id = ?`, id); err != nil {
+		return fmt.Errorf("error deleting session: %w", err)
+	}
+	return nil
+}
+
+// AppendMessage adds a new message as a child of parentID (empty for
+// the first message in a branch) and moves the session head to it.
+func (s *Store) AppendMessage(id, sessionID, parentID, role string, content []history.ContentBlock, inputTokens, outputTokens int) (*Message, error) {
+	contentJSON, err := json.Marshal(content)
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling message content: %w", err)
+	}
+
+	now := time.Now().UTC()
+	message := &Message{
+		ID:           id,
+		SessionID:    sessionID,
+		ParentID:     parentID,
+		Role:         role,
+		Content:      content,
+		InputTokens:  inputTokens,
+		OutputTokens: outputTokens,
+		CreatedAt:    now,
+	}
+
+	_, err = s.db.Exec(
+		`INSERT INTO messages (id, session_id, parent_id, role, content, input_tokens, output_tokens, created_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		message.ID, message.SessionID, message.ParentID, message.Role, contentJSON,
+		message.InputTokens, message.OutputTokens, now.Format(time.RFC3339Nano),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error appending message: %w", err)
+	}
+
+	_, err = s.db.Exec(
+		`UPDATE sessions SET head_id = ?, updated_at = ? WHERE id = ?`,
+		message.ID, now.Format(time.RFC3339Nano), sessionID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error updating session head: %w", err)
+	}
+
+	return message, nil
+}
+
+// Branch walks from leafID up to the root and returns the messages in
+// chronological order, the linear history a prompt is built from.
+func (s *Store) Branch(sessionID, leafID string) ([]*Message, error) {
+	all, err := s.messagesByID(sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	var branch []*Message
+	for id := leafID; id != ""; {
+		message, ok := all[id]
+		if !ok {
+			break
+		}
+		branch = append([]*Message{message}, branch...)
+		id = message.ParentID
+	}
+	return branch, nil
+}
+
+// Tree returns every message in a session, letting a caller reconstruct
+// the full branch DAG from ParentID links.
+func (s *Store) Tree(sessionID string) ([]*Message, error) {
+	byID, err := s.messagesByID(sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	messages := make([]*Message, 0, len(byID))
+	for _, message := range byID {
+		messages = append(messages, message)
+	}
+	return messages, nil
+}
+
+// UsageRecord is one turn's persisted token counts, the durable
+// counterpart of the server package's in-memory usage log.
+type UsageRecord struct {
+	SessionID    string
+	Model        string
+	InputTokens  int
+	OutputTokens int
+	CreatedAt    time.Time
+}
+
+// RecordUsage appends a turn's token counts for a session+model pair.
+// Like messages, usage is append-only and summed by the caller at
+// query time rather than kept as a running total, so it survives a
+// restart without losing the ability to report a windowed total.
+func (s *Store) RecordUsage(sessionID, model string, inputTokens, outputTokens int) error {
+	_, err := s.db.Exec(
+		`INSERT INTO usage (session_id, model, input_tokens, output_tokens, created_at) VALUES (?, ?, ?, ?, ?)`,
+		sessionID, model, inputTokens, outputTokens, time.Now().UTC().Format(time.RFC3339Nano),
+	)
+	if err != nil {
+		return fmt.Errorf("error recording usage: %w", err)
+	}
+	return nil
+}
+
+// Usage returns persisted usage records, optionally narrowed to a
+// single session, leaving summation by a "since" timestamp to the
+// caller.
+func (s *Store) Usage(sessionID string) ([]*UsageRecord, error) {
+	query := `SELECT session_id, model, input_tokens, output_tokens, created_at FROM usage`
+	args := []interface{}{}
+	if sessionID != "" {
+		query += ` WHERE session_id = ?`
+		args = append(args, sessionID)
+	}
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error loading usage: %w", err)
+	}
+	defer rows.Close()
+
+	var result []*UsageRecord
+	for rows.Next() {
+		var record UsageRecord
+		var createdAt string
+		if err := rows.Scan(&record.SessionID, &record.Model, &record.InputTokens, &record.OutputTokens, &createdAt); err != nil {
+			return nil, fmt.Errorf("error scanning usage record: %w", err)
+		}
+		record.CreatedAt, _ = time.Parse(time.RFC3339Nano, createdAt)
+		result = append(result, &record)
+	}
+	return result, rows.Err()
+}
+
+func (s *Store) messagesByID(sessionID string) (map[string]*Message, error) {
+	rows, err := s.db.Query(
+		`SELECT id, session_id, parent_id, role, content, input_tokens, output_tokens, created_at FROM messages WHERE session_id = ?`,
+		sessionID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error loading messages: %w", err)
+	}
+	defer rows.Close()
+
+	result := make(map[string]*Message)
+	for rows.Next() {
+		var message Message
+		var contentJSON, createdAt string
+		if err := rows.Scan(
+			&message.ID, &message.SessionID, &message.ParentID, &message.Role, &contentJSON,
+			&message.InputTokens, &message.OutputTokens, &createdAt,
+		); err != nil {
+			return nil, fmt.Errorf("error scanning message: %w", err)
+		}
+		if err := json.Unmarshal([]byte(contentJSON), &message.Content); err != nil {
+			return nil, fmt.Errorf("error unmarshaling message content: %w", err)
+		}
+		message.CreatedAt, _ = time.Parse(time.RFC3339Nano, createdAt)
+		result[message.ID] = &message
+	}
+	return result, rows.Err()
+}
@@ -0,0 +1,177 @@
+package sessions
+
+import (
+	"path/filepath"
+	"testing"
+
+	"mcphost-server/pkg/history"
+)
+
+func openTestStore(t *testing.T) *Store {
+	t.Helper()
+	store, err := Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+func textContent(text string) []history.ContentBlock {
+	return []history.ContentBlock{{Type: "text", Text: text}}
+}
+
+func TestAppendMessageAdvancesHead(t *testing.T) {
+	store := openTestStore(t)
+
+	if _, err := store.CreateSession("s1", "test-model", ""); err != nil {
+		t.Fatalf("CreateSession: %v", err)
+	}
+
+	first, err := store.AppendMessage("m1", "s1", "", "user", textContent("hi"), 0, 0)
+	if err != nil {
+		t.Fatalf("AppendMessage(m1): %v", err)
+	}
+
+	session, err := store.GetSession("s1")
+	if err != nil {
+		t.Fatalf("GetSession: %v", err)
+	}
+	if session.HeadID != first.ID {
+		t.Fatalf("HeadID after first message = %q, want %q", session.HeadID, first.ID)
+	}
+
+	if _, err := store.AppendMessage("m2", "s1", first.ID, "assistant", textContent("hello"), 0, 0); err != nil {
+		t.Fatalf("AppendMessage(m2): %v", err)
+	}
+
+	session, err = store.GetSession("s1")
+	if err != nil {
+		t.Fatalf("GetSession: %v", err)
+	}
+	if session.HeadID != "m2" {
+		t.Fatalf("HeadID after second message = %q, want m2", session.HeadID)
+	}
+}
+
+// TestBranchFollowsEditedParent builds a DAG with a branch point - m1 ->
+// m2 is the original path, m1 -> m2b is an edited alternative - and
+// checks Branch returns only the messages on the requested leaf's path.
+func TestBranchFollowsEditedParent(t *testing.T) {
+	store := openTestStore(t)
+
+	if _, err := store.CreateSession("s1", "test-model", ""); err != nil {
+		t.Fatalf("CreateSession: %v", err)
+	}
+
+	if _, err := store.AppendMessage("m1", "s1", "", "user", textContent("root"), 0, 0); err != nil {
+		t.Fatalf("AppendMessage(m1): %v", err)
+	}
+	if _, err := store.AppendMessage("m2", "s1", "m1", "assistant", textContent("original"), 0, 0); err != nil {
+		t.Fatalf("AppendMessage(m2): %v", err)
+	}
+	if _, err := store.AppendMessage("m2b", "s1", "m1", "assistant", textContent("edited"), 0, 0); err != nil {
+		t.Fatalf("AppendMessage(m2b): %v", err)
+	}
+
+	branch, err := store.Branch("s1", "m2")
+	if err != nil {
+		t.Fatalf("Branch(m2): %v", err)
+	}
+	if got := idsOf(branch); !equalIDs(got, []string{"m1", "m2"}) {
+		t.Errorf("Branch(m2) = %v, want [m1 m2]", got)
+	}
+
+	branch, err = store.Branch("s1", "m2b")
+	if err != nil {
+		t.Fatalf("Branch(m2b): %v", err)
+	}
+	if got := idsOf(branch); !equalIDs(got, []string{"m1", "m2b"}) {
+		t.Errorf("Branch(m2b) = %v, want [m1 m2b]", got)
+	}
+
+	tree, err := store.Tree("s1")
+	if err != nil {
+		t.Fatalf("Tree: %v", err)
+	}
+	if len(tree) != 3 {
+		t.Errorf("Tree returned %d messages, want 3 (m1, m2, m2b)", len(tree))
+	}
+}
+
+func TestBranchUnknownLeafReturnsEmpty(t *testing.T) {
+	store := openTestStore(t)
+
+	if _, err := store.CreateSession("s1", "test-model", ""); err != nil {
+		t.Fatalf("CreateSession: %v", err)
+	}
+	if _, err := store.AppendMessage("m1", "s1", "", "user", textContent("root"), 0, 0); err != nil {
+		t.Fatalf("AppendMessage(m1): %v", err)
+	}
+
+	branch, err := store.Branch("s1", "does-not-exist")
+	if err != nil {
+		t.Fatalf("Branch: %v", err)
+	}
+	if len(branch) != 0 {
+		t.Errorf("Branch(unknown leaf) = %v, want empty", branch)
+	}
+}
+
+func TestDeleteSessionRemovesMessagesAndUsage(t *testing.T) {
+	store := openTestStore(t)
+
+	if _, err := store.CreateSession("s1", "test-model", ""); err != nil {
+		t.Fatalf("CreateSession: %v", err)
+	}
+	if _, err := store.AppendMessage("m1", "s1", "", "user", textContent("root"), 0, 0); err != nil {
+		t.Fatalf("AppendMessage(m1): %v", err)
+	}
+	if err := store.RecordUsage("s1", "test-model", 10, 20); err != nil {
+		t.Fatalf("RecordUsage: %v", err)
+	}
+
+	if err := store.DeleteSession("s1"); err != nil {
+		t.Fatalf("DeleteSession: %v", err)
+	}
+
+	if _, err := store.GetSession("s1"); err == nil {
+		t.Fatal("GetSession after delete succeeded, want error")
+	}
+
+	tree, err := store.Tree("s1")
+	if err != nil {
+		t.Fatalf("Tree after delete: %v", err)
+	}
+	if len(tree) != 0 {
+		t.Errorf("Tree after delete = %v, want empty", tree)
+	}
+
+	usage, err := store.Usage("s1")
+	if err != nil {
+		t.Fatalf("Usage after delete: %v", err)
+	}
+	if len(usage) != 0 {
+		t.Errorf("Usage after delete = %v, want empty", usage)
+	}
+}
+
+func idsOf(messages []*Message) []string {
+	ids := make([]string, len(messages))
+	for i, m := range messages {
+		ids[i] = m.ID
+	}
+	return ids
+}
+
+func equalIDs(got, want []string) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			return false
+		}
+	}
+	return true
+}
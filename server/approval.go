@@ -0,0 +1,195 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+
+	"mcphost-server/pkg/agents"
+	"mcphost-server/pkg/history"
+
+	"github.com/charmbracelet/log"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// PendingToolCall is a tool_use block the model requested that has not
+// been executed yet because it is not on the auto_approve list.
+type PendingToolCall struct {
+	ID    string `json:"tool_use_id"`
+	Name  string `json:"name"`
+	Input []byte `json:"arguments"`
+}
+
+// PendingRun holds everything needed to resume a conversation once its
+// pending tool calls have been approved, denied, or edited.
+type PendingRun struct {
+	RunID     string
+	AgentName string
+	SessionID string
+	Messages  []history.HistoryMessage
+	ToolCalls []PendingToolCall
+
+	// PersistedCount is how many of Messages were already written to
+	// sessionStore before this run paused - the rest only exist
+	// in-memory until the pending tool calls are resolved.
+	PersistedCount int
+}
+
+// Approval is the caller's decision for a single pending tool call.
+type Approval struct {
+	Decision  string                 `json:"decision"`
+	Arguments map[string]interface{} `json:"arguments,omitempty"`
+}
+
+var (
+	pendingRunsMu sync.Mutex
+	pendingRuns   = make(map[string]*PendingRun)
+
+	autoApproveTools []string
+)
+
+func isAutoApproved(toolName string) bool {
+	for _, name := range autoApproveTools {
+		if name == toolName {
+			return true
+		}
+	}
+	return false
+}
+
+func savePendingRun(run *PendingRun) {
+	pendingRunsMu.Lock()
+	defer pendingRunsMu.Unlock()
+	pendingRuns[run.RunID] = run
+}
+
+func takePendingRun(runID string) (*PendingRun, bool) {
+	pendingRunsMu.Lock()
+	defer pendingRunsMu.Unlock()
+	run, ok := pendingRuns[runID]
+	if ok {
+		delete(pendingRuns, runID)
+	}
+	return run, ok
+}
+
+// executeTool runs a single namespaced tool call against its MCP client
+// and returns the tool_result block to record in history. agent's
+// allow-list is re-checked here, not just when the tool list offered to
+// the model was filtered, so a model (or a hand-edited approval body)
+// can't reach a tool outside the active agent's scope by naming it
+// directly.
+func executeTool(agent agents.Agent, toolUseID, namespacedName string, toolArgs map[string]interface{}) history.ContentBlock {
+	if !agent.IsToolAllowed(namespacedName) {
+		log.Warn("Tool not allowed for agent", "tool", namespacedName)
+		return history.ContentBlock{
+			Type:      "tool_result",
+			ToolUseID: toolUseID,
+			Content: []history.ContentBlock{{
+				Type: "text",
+				Text: fmt.Sprintf("tool not allowed for this agent: %s", namespacedName),
+			}},
+		}
+	}
+
+	parts := strings.Split(namespacedName, "__")
+	if len(parts) != 2 {
+		log.Warn("Invalid tool name format", "name", namespacedName)
+		return history.ContentBlock{
+			Type:      "tool_result",
+			ToolUseID: toolUseID,
+			Content: []history.ContentBlock{{
+				Type: "text",
+				Text: fmt.Sprintf("invalid tool name format: %s", namespacedName),
+			}},
+		}
+	}
+
+	serverName, toolName := parts[0], parts[1]
+	mcpClient, ok := modelMcpClients[serverName]
+	if !ok {
+		log.Warn("Server not found", "server", serverName)
+		return history.ContentBlock{
+			Type:      "tool_result",
+			ToolUseID: toolUseID,
+			Content: []history.ContentBlock{{
+				Type: "text",
+				Text: fmt.Sprintf("server not found: %s", serverName),
+			}},
+		}
+	}
+
+	req := mcp.CallToolRequest{}
+	req.Params.Name = toolName
+	req.Params.Arguments = toolArgs
+	toolResultPtr, err := mcpClient.CallTool(context.Background(), req)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error calling tool %s: %v", toolName, err)
+		log.Warn("Error calling tool", "error", errMsg)
+		return history.ContentBlock{
+			Type:      "tool_result",
+			ToolUseID: toolUseID,
+			Content: []history.ContentBlock{{
+				Type: "text",
+				Text: errMsg,
+			}},
+		}
+	}
+
+	return history.ContentBlock{
+		Type:      "tool_result",
+		ToolUseID: toolUseID,
+		Content:   toolResultPtr.Content,
+	}
+}
+
+func deniedToolResult(toolUseID string) history.ContentBlock {
+	return history.ContentBlock{
+		Type:      "tool_result",
+		ToolUseID: toolUseID,
+		Content: []history.ContentBlock{{
+			Type: "text",
+			Text: "tool call denied by caller",
+		}},
+	}
+}
+
+// resolvePendingRun applies the caller's approvals to a pending run,
+// appends the resulting tool results to its message history, and
+// re-enters the model loop to continue the conversation.
+func resolvePendingRun(run *PendingRun, approvals map[string]Approval) (*PendingRun, error) {
+	agent := modelAgents[run.AgentName]
+	var toolResults []history.ContentBlock
+
+	for _, call := range run.ToolCalls {
+		approval, ok := approvals[call.ID]
+		if !ok {
+			approval = Approval{Decision: "deny"}
+		}
+
+		switch approval.Decision {
+		case "allow":
+			var toolArgs map[string]interface{}
+			if err := json.Unmarshal(call.Input, &toolArgs); err != nil {
+				log.Warn("Error parsing tool arguments", "error", err)
+				continue
+			}
+			toolResults = append(toolResults, executeTool(agent, call.ID, call.Name, toolArgs))
+
+		case "edit":
+			toolResults = append(toolResults, executeTool(agent, call.ID, call.Name, approval.Arguments))
+
+		default:
+			toolResults = append(toolResults, deniedToolResult(call.ID))
+		}
+	}
+
+	run.Messages = append(run.Messages, history.HistoryMessage{
+		Role:    "user",
+		Content: toolResults,
+	})
+
+	return runPrompt("", run.AgentName, run.SessionID, &run.Messages)
+}
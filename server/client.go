@@ -8,6 +8,7 @@ import (
 	"path/filepath"
 	"time"
 
+	"mcphost-server/pkg/agents"
 	"mcphost-server/pkg/llm"
 
 	"github.com/charmbracelet/log"
@@ -15,14 +16,39 @@ import (
 	"github.com/mark3labs/mcp-go/mcp"
 )
 
+// toolInvoker is the subset of an MCP client's behavior runPrompt
+// actually depends on. It's satisfied by mcpclient.MCPClient as well as
+// in-process "virtual" servers like pkg/builtintools, so the model-side
+// code doesn't care whether a tool is remote-stdio-MCP or local.
+type toolInvoker interface {
+	CallTool(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error)
+	ListTools(ctx context.Context, req mcp.ListToolsRequest) (*mcp.ListToolsResult, error)
+	Close() error
+}
+
 type MCPConfig struct {
-	MCPServers map[string]ServerConfig `json:"mcpServers"`
+	MCPServers    map[string]ServerConfig `json:"mcpServers"`
+	Agents        map[string]agents.Agent `json:"agents,omitempty"`
+	AutoApprove   []string                `json:"auto_approve,omitempty"`
+	WorkspaceRoot string                  `json:"workspaceRoot,omitempty"`
+	Pricing       map[string]PriceTier    `json:"pricing,omitempty"`
 }
 
 type ServerConfig struct {
 	Command string            `json:"command"`
 	Args    []string          `json:"args"`
 	Env     map[string]string `json:"env,omitempty"`
+
+	// Transport selects how this server is reached: "stdio" (default,
+	// launches Command as a subprocess), "sse", or "http" for hosted
+	// MCP servers reachable over the network.
+	Transport string            `json:"transport,omitempty"`
+	URL       string            `json:"url,omitempty"`
+	Headers   map[string]string `json:"headers,omitempty"`
+
+	// Auth, if set, is sent as the Authorization header on sse/http
+	// requests (e.g. "Bearer <token>"), merged alongside Headers.
+	Auth string `json:"auth,omitempty"`
 }
 
 func loadMCPConfig() (*MCPConfig, error) {
@@ -75,21 +101,57 @@ func loadMCPConfig() (*MCPConfig, error) {
 	return &config, nil
 }
 
-func createMCPClients(
-	config *MCPConfig,
-) (map[string]*mcpclient.StdioMCPClient, error) {
-	clients := make(map[string]*mcpclient.StdioMCPClient)
+// requestHeaders merges server.Auth into server.Headers as a bearer
+// Authorization header, without mutating the config's own map.
+func requestHeaders(server ServerConfig) map[string]string {
+	if server.Auth == "" {
+		return server.Headers
+	}
 
-	for name, server := range config.MCPServers {
+	headers := make(map[string]string, len(server.Headers)+1)
+	for k, v := range server.Headers {
+		headers[k] = v
+	}
+	headers["Authorization"] = server.Auth
+	return headers
+}
+
+// newTransportClient builds the underlying client for a single server,
+// dispatching on its configured transport. "stdio" is the default so
+// existing configs without a "transport" field keep working unchanged.
+func newTransportClient(server ServerConfig) (mcpclient.MCPClient, error) {
+	switch server.Transport {
+	case "", "stdio":
 		var env []string
 		for k, v := range server.Env {
 			env = append(env, fmt.Sprintf("%s=%s", k, v))
 		}
-		client, err := mcpclient.NewStdioMCPClient(
-			server.Command,
-			env,
-			server.Args...,
-		)
+		return mcpclient.NewStdioMCPClient(server.Command, env, server.Args...)
+
+	case "sse":
+		if server.URL == "" {
+			return nil, fmt.Errorf("sse transport requires a url")
+		}
+		return mcpclient.NewSSEMCPClient(server.URL, mcpclient.WithHeaders(requestHeaders(server)))
+
+	case "http":
+		if server.URL == "" {
+			return nil, fmt.Errorf("http transport requires a url")
+		}
+		return mcpclient.NewStreamableHttpClient(server.URL, mcpclient.WithHTTPHeaders(requestHeaders(server)))
+
+	default:
+		return nil, fmt.Errorf("unsupported transport: %s", server.Transport)
+	}
+}
+
+func createMCPClients(
+	config *MCPConfig,
+) (map[string]toolInvoker, error) {
+	clients := make(map[string]toolInvoker)
+
+	for name, server := range config.MCPServers {
+		client, err := newTransportClient(server)
 		if err != nil {
 			for _, c := range clients {
 				c.Close()
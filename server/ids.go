@@ -0,0 +1,17 @@
+package server
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+)
+
+// newID generates a short random hex identifier, used for run IDs,
+// session IDs, and message IDs.
+func newID() (string, error) {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("error generating id: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -10,13 +10,14 @@ import (
 
 	"github.com/charmbracelet/log"
 
+	"mcphost-server/pkg/agents"
+	"mcphost-server/pkg/builtintools"
 	"mcphost-server/pkg/history"
 	"mcphost-server/pkg/llm"
 	"mcphost-server/pkg/llm/anthropic"
 	"mcphost-server/pkg/llm/ollama"
 	"mcphost-server/pkg/llm/openai"
 
-	mcpclient "github.com/mark3labs/mcp-go/client"
 	"github.com/mark3labs/mcp-go/mcp"
 )
 
@@ -29,9 +30,10 @@ var (
 	openaiBaseURL    string
 	openaiAPIKey     string
 	modelProvider    llm.Provider
-	modelMcpClients  map[string]*mcpclient.StdioMCPClient
+	modelMcpClients  map[string]toolInvoker
 	modelTools       []llm.Tool
 	modelMessages    []history.HistoryMessage
+	modelAgents      map[string]agents.Agent
 )
 
 var debugMode bool = false
@@ -84,14 +86,36 @@ func createProvider(modelString string) (llm.Provider, error) {
 	}
 }
 
-// Method implementations for simpleMessage
+// modelUsageKey is the "provider:model" key usage is tracked and
+// priced under. An agent's Model field is currently descriptive only
+// (runMCPHost builds a single modelProvider for the whole server, and
+// nothing switches providers per agent), so usage is always attributed
+// to the server's configured model rather than agent.Model - otherwise
+// tokens actually billed against modelFlag would be priced and reported
+// under a tier the agent merely requested.
+func modelUsageKey(agent agents.Agent) string {
+	return modelFlag
+}
+
+// runPrompt calls the model and, for any tool_use blocks it returns,
+// either executes them immediately (when the tool is on the
+// auto_approve list) or parks them in a PendingRun for a caller to
+// approve, deny, or edit via POST /prompt/approve. It returns a
+// non-nil *PendingRun only when tool calls are awaiting approval.
 func runPrompt(
 	prompt string,
+	agentName string,
+	sessionID string,
 	messages *[]history.HistoryMessage,
-) error {
+) (*PendingRun, error) {
 	var message llm.Message
 	var err error
 
+	agent, ok := modelAgents[agentName]
+	if agentName != "" && !ok {
+		return nil, fmt.Errorf("unknown agent: %s", agentName)
+	}
+
 	if prompt != "" {
 		log.Info("User prompt", "User", prompt)
 		*messages = append(
@@ -114,21 +138,32 @@ func runPrompt(
 	// Ensure modelProvider is initialized before using it
 	if modelProvider == nil {
 		log.Error("Model provider not initialized")
-		return fmt.Errorf("model provider not initialized")
+		return nil, fmt.Errorf("model provider not initialized")
 	}
 
 	message, err = modelProvider.CreateMessage(
 		context.Background(),
 		prompt,
+		agent.SystemPrompt,
 		llmMessages,
-		modelTools,
+		agent.FilterTools(modelTools),
 	)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	var messageContent []history.ContentBlock
 	var toolResults []history.ContentBlock
+	var pendingCalls []PendingToolCall
+
+	inputTokens, outputTokens := message.GetUsage()
+	if inputTokens > 0 || outputTokens > 0 {
+		log.Info("Usage statistics",
+			"input_tokens", inputTokens,
+			"output_tokens", outputTokens,
+			"total_tokens", inputTokens+outputTokens)
+	}
+	recordUsage(sessionID, modelUsageKey(agent), inputTokens, outputTokens)
 
 	// Add text content
 	if message.GetContent() != "" {
@@ -151,28 +186,12 @@ func runPrompt(
 			Input: input,
 		})
 
-		// Log usage statistics if available
-		inputTokens, outputTokens := message.GetUsage()
-		if inputTokens > 0 || outputTokens > 0 {
-			log.Info("Usage statistics",
-				"input_tokens", inputTokens,
-				"output_tokens", outputTokens,
-				"total_tokens", inputTokens+outputTokens)
-		}
-
-		parts := strings.Split(toolCall.GetName(), "__")
-		if len(parts) != 2 {
-			log.Warn(
-				"Invalid tool name format",
-				"name", toolCall.GetName(),
-			)
-			continue
-		}
-
-		serverName, toolName := parts[0], parts[1]
-		mcpClient, ok := modelMcpClients[serverName]
-		if !ok {
-			log.Warn("Server not found", "server", serverName)
+		if !isAutoApproved(toolCall.GetName()) {
+			pendingCalls = append(pendingCalls, PendingToolCall{
+				ID:    toolCall.GetID(),
+				Name:  toolCall.GetName(),
+				Input: input,
+			})
 			continue
 		}
 
@@ -182,72 +201,44 @@ func runPrompt(
 			continue
 		}
 
-		var toolResultPtr *mcp.CallToolResult
+		toolResults = append(toolResults, executeTool(agent, toolCall.GetID(), toolCall.GetName(), toolArgs))
+	}
 
-		req := mcp.CallToolRequest{}
-		req.Params.Name = toolName
-		req.Params.Arguments = toolArgs
-		toolResultPtr, err = mcpClient.CallTool(
-			context.Background(),
-			req,
-		)
+	*messages = append(*messages, history.HistoryMessage{
+		Role:         message.GetRole(),
+		Content:      messageContent,
+		InputTokens:  inputTokens,
+		OutputTokens: outputTokens,
+	})
 
+	if len(pendingCalls) > 0 {
+		runID, err := newID()
 		if err != nil {
-			errMsg := fmt.Sprintf(
-				"Error calling tool %s: %v",
-				toolName,
-				err,
-			)
-			log.Warn("Error calling tool", "error", errMsg)
+			return nil, err
+		}
 
-			// Add error message as tool result
-			messageContent = append(messageContent, history.ContentBlock{
-				Type:      "tool_result",
-				ToolUseID: toolCall.GetID(),
-				Content: []history.ContentBlock{{
-					Type: "text",
-					Text: errMsg,
-				}},
-			})
-			continue
+		run := &PendingRun{
+			RunID:          runID,
+			AgentName:      agentName,
+			SessionID:      sessionID,
+			Messages:       *messages,
+			ToolCalls:      pendingCalls,
+			PersistedCount: len(*messages),
 		}
 
-		toolResult := *toolResultPtr
-
-		if toolResult.Content != nil {
-			log.Info("Raw tool result content", "content", toolResult.Content)
-
-			// Create the tool result block
-			resultBlock := history.ContentBlock{
-				Type:      "tool_result",
-				ToolUseID: toolCall.GetID(),
-				Content:   toolResult.Content,
-			}
-
-			// Extract text content
-			var resultText string
-			// Handle array content directly since we know it's []interface{}
-			for _, item := range toolResult.Content {
-				if contentMap, ok := item.(map[string]interface{}); ok {
-					if text, ok := contentMap["text"]; ok {
-						resultText += fmt.Sprintf("%v ", text)
-					}
-				}
-			}
-
-			resultBlock.Text = strings.TrimSpace(resultText)
-			log.Info("created tool result block",
-				"block", resultBlock,
-				"tool_id", toolCall.GetID())
-
-			toolResults = append(toolResults, resultBlock)
+		if len(toolResults) > 0 {
+			// Tool calls already auto-approved in this turn are folded
+			// into the same pending run so they're only applied once
+			// the remaining calls are resolved.
+			run.Messages = append(run.Messages, history.HistoryMessage{
+				Role:    "user",
+				Content: toolResults,
+			})
 		}
-	}
 
-	*messages = append(*messages, history.HistoryMessage{
-		Role:    message.GetRole(),
-		Content: messageContent,
-	})
+		savePendingRun(run)
+		return run, nil
+	}
 
 	if len(toolResults) > 0 {
 		*messages = append(*messages, history.HistoryMessage{
@@ -255,11 +246,13 @@ func runPrompt(
 			Content: toolResults,
 		})
 		// Make another call to get Claude's response to the tool results
-		return runPrompt("", messages)
+		return runPrompt("", agentName, sessionID, messages)
 	}
 
-	modelMessages = append(modelMessages, *messages...)
-	return nil
+	if sessionStore == nil {
+		modelMessages = append(modelMessages, *messages...)
+	}
+	return nil, nil
 }
 
 func runMCPHost() error {
@@ -291,11 +284,34 @@ func runMCPHost() error {
 		log.Error("Error loading MCP config", "error", err)
 	}
 
+	modelAgents = mcpConfig.Agents
+	log.Info("Agents loaded", "count", len(modelAgents))
+
+	autoApproveTools = mcpConfig.AutoApprove
+	log.Info("Auto-approved tools loaded", "count", len(autoApproveTools))
+
+	setPricing(mcpConfig.Pricing)
+	log.Info("Pricing table loaded", "count", len(mcpConfig.Pricing))
+
 	modelMcpClients, err = createMCPClients(mcpConfig)
 	if err != nil {
 		log.Error("Error creating MCP clients", "error", err)
 	}
 
+	workspaceRoot := mcpConfig.WorkspaceRoot
+	if workspaceRoot == "" {
+		workspaceRoot = "."
+	}
+	builtinServer, err := builtintools.New(workspaceRoot)
+	if err != nil {
+		log.Error("Error creating builtin toolbox", "error", err)
+	} else {
+		if modelMcpClients == nil {
+			modelMcpClients = make(map[string]toolInvoker)
+		}
+		modelMcpClients[builtintools.ServerName] = builtinServer
+	}
+
 	log.Info("MCP clients created", "count", len(modelMcpClients))
 	for name := range modelMcpClients {
 		log.Info("Server connected", "name", name)
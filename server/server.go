@@ -8,6 +8,7 @@ import (
 	"strconv"
 
 	"mcphost-server/pkg/history"
+	"mcphost-server/pkg/llm"
 
 	"github.com/charmbracelet/log"
 	"github.com/joho/godotenv"
@@ -51,30 +52,138 @@ func (s *Server) healthHandler(w http.ResponseWriter, r *http.Request) {
 	fmt.Fprintf(w, "Server is healthy")
 }
 
+// promptHandler is deprecated in favor of POST /sessions/{id}/prompt.
+// It survives as sugar over a single "default" session so existing
+// callers keep working against the old global conversation.
 func (s *Server) promptHandler(w http.ResponseWriter, r *http.Request) {
-	messages := make([]history.HistoryMessage, 0)
 	prompt := r.FormValue("prompt")
 	if prompt == "" {
 		http.Error(w, "Prompt is required", http.StatusBadRequest)
 		return
 	}
+	agentName := r.URL.Query().Get("agent")
+
+	if sessionStore == nil {
+		messages := make([]history.HistoryMessage, 0)
+		run, err := runPrompt(prompt, agentName, defaultSessionID, &messages)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Error executing prompt: %v", err), http.StatusInternalServerError)
+			return
+		}
+		writePromptResult(w, run, messages)
+		return
+	}
+
+	s.sessionPromptHandler(w, r, defaultSessionID)
+}
+
+// approveRequest is the body of POST /prompt/approve.
+type approveRequest struct {
+	RunID     string              `json:"run_id"`
+	Approvals map[string]Approval `json:"approvals"`
+}
+
+func (s *Server) promptApproveHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
 
-	if err := runPrompt(prompt, &messages); err != nil {
-		http.Error(w, fmt.Sprintf("Error executing prompt: %v", err), http.StatusInternalServerError)
+	var body approveRequest
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, fmt.Sprintf("Invalid request body: %v", err), http.StatusBadRequest)
 		return
 	}
 
-	jsonData, err := json.Marshal(messages)
+	run, ok := takePendingRun(body.RunID)
+	if !ok {
+		http.Error(w, fmt.Sprintf("Unknown run_id: %s", body.RunID), http.StatusNotFound)
+		return
+	}
+
+	nextRun, err := resolvePendingRun(run, body.Approvals)
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Error marshaling response: %v", err), http.StatusInternalServerError)
+		http.Error(w, fmt.Sprintf("Error resolving tool calls: %v", err), http.StatusInternalServerError)
 		return
 	}
 
+	if sessionStore != nil {
+		persistedThrough := len(run.Messages)
+		if nextRun != nil {
+			persistedThrough = nextRun.PersistedCount
+		}
+
+		session, err := sessionStore.GetSession(run.SessionID)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if err := persistNewMessages(run.SessionID, session.HeadID, run.PersistedCount, run.Messages[:persistedThrough]); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	writePromptResult(w, nextRun, run.Messages)
+}
+
+// writePromptResult renders either the pending-approval shape (when run
+// is non-nil) or the finished message history.
+func writePromptResult(w http.ResponseWriter, run *PendingRun, messages []history.HistoryMessage) {
 	w.Header().Set("Content-Type", "application/json")
+
+	var jsonData []byte
+	var err error
+	if run != nil {
+		jsonData, err = json.Marshal(struct {
+			RunID            string            `json:"run_id"`
+			PendingToolCalls []PendingToolCall `json:"pending_tool_calls"`
+		}{RunID: run.RunID, PendingToolCalls: run.ToolCalls})
+	} else {
+		jsonData, err = json.Marshal(messages)
+	}
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error marshaling response: %v", err), http.StatusInternalServerError)
+		return
+	}
+
 	w.WriteHeader(http.StatusOK)
 	w.Write(jsonData)
 }
 
+func (s *Server) promptStreamHandler(w http.ResponseWriter, r *http.Request) {
+	prompt := r.FormValue("prompt")
+	if prompt == "" {
+		http.Error(w, "Prompt is required", http.StatusBadRequest)
+		return
+	}
+	agentName := r.URL.Query().Get("agent")
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	messages := make([]history.HistoryMessage, 0)
+	events := make(chan llm.Event)
+	go streamPrompt(prompt, agentName, defaultSessionID, &messages, events)
+
+	for event := range events {
+		payload, err := json.Marshal(event)
+		if err != nil {
+			log.Error("Error marshaling stream event", "error", err)
+			continue
+		}
+		fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, payload)
+		flusher.Flush()
+	}
+}
+
 func (s *Server) toolHandler(w http.ResponseWriter, r *http.Request) {
 	jsonData, err := json.Marshal(modelTools)
 	if err != nil {
@@ -87,7 +196,13 @@ func (s *Server) toolHandler(w http.ResponseWriter, r *http.Request) {
 	w.Write(jsonData)
 }
 
+// historyHandler is deprecated in favor of GET /sessions/{id}/messages.
 func (s *Server) historyHandler(w http.ResponseWriter, r *http.Request) {
+	if sessionStore != nil {
+		s.sessionMessagesHandler(w, r, defaultSessionID)
+		return
+	}
+
 	var returnMessages []history.HistoryMessage
 	log.Info("History requested", "messageWindow", messageWindow, "modelMessages", len(modelMessages))
 	if len(modelMessages) > messageWindow {
@@ -116,9 +231,18 @@ func (s *Server) Start() error {
 	mux.HandleFunc("/tool", s.toolHandler)
 	mux.HandleFunc("/history", s.historyHandler)
 	mux.HandleFunc("/prompt", s.promptHandler)
+	mux.HandleFunc("/prompt/stream", s.promptStreamHandler)
+	mux.HandleFunc("/prompt/approve", s.promptApproveHandler)
+	mux.HandleFunc("/usage", s.usageHandler)
+	mux.HandleFunc("/sessions", s.sessionsHandler)
+	mux.HandleFunc("/sessions/", s.sessionRouter)
 
 	runMCPHost()
 
+	if err := openSessionStore(); err != nil {
+		log.Error("Error opening session store", "error", err)
+	}
+
 	server := &http.Server{
 		Addr:    fmt.Sprintf(":%d", s.port),
 		Handler: mux,
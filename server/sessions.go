@@ -0,0 +1,296 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"mcphost-server/pkg/history"
+	"mcphost-server/pkg/sessions"
+)
+
+// sessionStore is the SQLite-backed replacement for the old global
+// modelMessages slice. It is nil until runMCPHost opens it, at which
+// point /prompt and /history become sugar over a default session.
+var sessionStore *sessions.Store
+
+const defaultSessionID = "default"
+
+func ensureDefaultSession() (*sessions.Session, error) {
+	session, err := sessionStore.GetSession(defaultSessionID)
+	if err == nil {
+		return session, nil
+	}
+	return sessionStore.CreateSession(defaultSessionID, modelFlag, "")
+}
+
+// branchMessages loads a session's head-to-root branch as history
+// messages runPrompt can extend.
+func branchMessages(sessionID string) ([]history.HistoryMessage, string, error) {
+	session, err := sessionStore.GetSession(sessionID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	nodes, err := sessionStore.Branch(sessionID, session.HeadID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	messages := make([]history.HistoryMessage, len(nodes))
+	for i, node := range nodes {
+		messages[i] = history.HistoryMessage{
+			Role:         node.Role,
+			Content:      node.Content,
+			InputTokens:  node.InputTokens,
+			OutputTokens: node.OutputTokens,
+		}
+	}
+	return messages, session.HeadID, nil
+}
+
+// persistNewMessages appends messages produced since len(before) onto
+// the session, chaining each one off the previous via parentID.
+func persistNewMessages(sessionID, parentID string, before int, messages []history.HistoryMessage) error {
+	for _, message := range messages[before:] {
+		id, err := newID()
+		if err != nil {
+			return err
+		}
+		if _, err := sessionStore.AppendMessage(
+			id, sessionID, parentID, message.Role, message.Content,
+			message.InputTokens, message.OutputTokens,
+		); err != nil {
+			return err
+		}
+		parentID = id
+	}
+	return nil
+}
+
+// openSessionStore opens the SQLite session store at MCP_SESSIONS_PATH
+// (defaulting to mcphost-sessions.db) and seeds the "default" session
+// that /prompt and /history are now sugar over.
+func openSessionStore() error {
+	path := os.Getenv("MCP_SESSIONS_PATH")
+	if path == "" {
+		path = "mcphost-sessions.db"
+	}
+
+	store, err := sessions.Open(path)
+	if err != nil {
+		return err
+	}
+	sessionStore = store
+
+	if _, err := ensureDefaultSession(); err != nil {
+		return fmt.Errorf("error seeding default session: %w", err)
+	}
+	return nil
+}
+
+func (s *Server) sessionsHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		s.createSessionHandler(w, r)
+	case http.MethodGet:
+		s.listSessionsHandler(w, r)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *Server) createSessionHandler(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Model string `json:"model"`
+		Agent string `json:"agent,omitempty"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, fmt.Sprintf("Invalid request body: %v", err), http.StatusBadRequest)
+		return
+	}
+	if body.Model == "" {
+		body.Model = modelFlag
+	}
+
+	id, err := newID()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	session, err := sessionStore.CreateSession(id, body.Model, body.Agent)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, session)
+}
+
+func (s *Server) listSessionsHandler(w http.ResponseWriter, r *http.Request) {
+	sessionList, err := sessionStore.ListSessions()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, sessionList)
+}
+
+func (s *Server) deleteSessionHandler(w http.ResponseWriter, r *http.Request, sessionID string) {
+	if err := sessionStore.DeleteSession(sessionID); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) sessionMessagesHandler(w http.ResponseWriter, r *http.Request, sessionID string) {
+	messages, _, err := branchMessages(sessionID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	writeJSON(w, messages)
+}
+
+func (s *Server) sessionTreeHandler(w http.ResponseWriter, r *http.Request, sessionID string) {
+	nodes, err := sessionStore.Tree(sessionID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, nodes)
+}
+
+func (s *Server) sessionPromptHandler(w http.ResponseWriter, r *http.Request, sessionID string) {
+	prompt := r.FormValue("prompt")
+	if prompt == "" {
+		http.Error(w, "Prompt is required", http.StatusBadRequest)
+		return
+	}
+	agentName := r.URL.Query().Get("agent")
+
+	messages, headID, err := branchMessages(sessionID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	before := len(messages)
+
+	run, err := runPrompt(prompt, agentName, sessionID, &messages)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error executing prompt: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	if err := persistNewMessages(sessionID, headID, before, messages); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	writePromptResult(w, run, messages)
+}
+
+// editMessageHandler creates a new branch from an edited message and
+// re-prompts down it, leaving the original branch untouched.
+func (s *Server) editMessageHandler(w http.ResponseWriter, r *http.Request, sessionID, msgID string) {
+	var body struct {
+		Content []history.ContentBlock `json:"content"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, fmt.Sprintf("Invalid request body: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	nodes, err := sessionStore.Tree(sessionID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	var parentID string
+	var role string
+	for _, node := range nodes {
+		if node.ID == msgID {
+			parentID, role = node.ParentID, node.Role
+			break
+		}
+	}
+	if role == "" {
+		http.Error(w, fmt.Sprintf("message not found: %s", msgID), http.StatusNotFound)
+		return
+	}
+
+	editedID, err := newID()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if _, err := sessionStore.AppendMessage(editedID, sessionID, parentID, role, body.Content, 0, 0); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	messages, _, err := branchMessages(sessionID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	before := len(messages)
+
+	agentName := r.URL.Query().Get("agent")
+	run, err := runPrompt("", agentName, sessionID, &messages)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error executing prompt: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	if err := persistNewMessages(sessionID, editedID, before, messages); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	writePromptResult(w, run, messages)
+}
+
+// sessionRouter dispatches /sessions/{id}/... paths since net/http's
+// ServeMux (pre-1.22 patterns) has no built-in path variables.
+func (s *Server) sessionRouter(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, "/sessions/")
+	parts := strings.Split(path, "/")
+
+	sessionID := parts[0]
+	if sessionID == "" {
+		http.NotFound(w, r)
+		return
+	}
+
+	switch {
+	case len(parts) == 1 && r.Method == http.MethodDelete:
+		s.deleteSessionHandler(w, r, sessionID)
+	case len(parts) == 2 && parts[1] == "messages" && r.Method == http.MethodGet:
+		s.sessionMessagesHandler(w, r, sessionID)
+	case len(parts) == 2 && parts[1] == "tree" && r.Method == http.MethodGet:
+		s.sessionTreeHandler(w, r, sessionID)
+	case len(parts) == 2 && parts[1] == "prompt" && r.Method == http.MethodPost:
+		s.sessionPromptHandler(w, r, sessionID)
+	case len(parts) == 4 && parts[1] == "messages" && parts[3] == "edit" && r.Method == http.MethodPost:
+		s.editMessageHandler(w, r, sessionID, parts[2])
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	jsonData, err := json.Marshal(v)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error marshaling response: %v", err), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(jsonData)
+}
@@ -0,0 +1,214 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"mcphost-server/pkg/history"
+	"mcphost-server/pkg/llm"
+
+	"github.com/charmbracelet/log"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// streamPrompt is the streaming counterpart of runPrompt. Instead of
+// blocking until the whole tool chain finishes, it forwards provider
+// events as they arrive and emits tool_use_start/tool_use_end around
+// each MCP CallTool, so long tool-heavy runs are observable in real
+// time over /prompt/stream.
+func streamPrompt(
+	prompt string,
+	agentName string,
+	sessionID string,
+	messages *[]history.HistoryMessage,
+	events chan<- llm.Event,
+) {
+	defer close(events)
+
+	agent, ok := modelAgents[agentName]
+	if agentName != "" && !ok {
+		events <- llm.Event{Type: llm.EventError, Err: fmt.Sprintf("unknown agent: %s", agentName)}
+		return
+	}
+
+	if modelProvider == nil {
+		events <- llm.Event{Type: llm.EventError, Err: "model provider not initialized"}
+		return
+	}
+
+	if prompt != "" {
+		*messages = append(*messages, history.HistoryMessage{
+			Role: "user",
+			Content: []history.ContentBlock{{
+				Type: "text",
+				Text: prompt,
+			}},
+		})
+	}
+
+	llmMessages := make([]llm.Message, len(*messages))
+	for i := range *messages {
+		llmMessages[i] = &(*messages)[i]
+	}
+
+	providerEvents, err := modelProvider.StreamMessage(
+		context.Background(),
+		prompt,
+		agent.SystemPrompt,
+		llmMessages,
+		agent.FilterTools(modelTools),
+	)
+	if err != nil {
+		events <- llm.Event{Type: llm.EventError, Err: err.Error()}
+		return
+	}
+
+	var messageContent []history.ContentBlock
+	var toolResults []history.ContentBlock
+	var inputTokens, outputTokens int
+
+	for event := range providerEvents {
+		switch event.Type {
+		case llm.EventTextDelta:
+			messageContent = appendTextDelta(messageContent, event.Text)
+			events <- event
+
+		case llm.EventToolUseEnd:
+			// event here just marks that the provider finished
+			// streaming this tool_use block; tool_use_start/_end sent
+			// to the client bracket the actual CallTool below instead,
+			// so they happen in the order they describe.
+			parts := strings.Split(event.ToolName, "__")
+			if len(parts) != 2 {
+				log.Warn("Invalid tool name format", "name", event.ToolName)
+				continue
+			}
+			serverName, toolName := parts[0], parts[1]
+
+			messageContent = append(messageContent, history.ContentBlock{
+				Type:  "tool_use",
+				ID:    event.ToolID,
+				Name:  event.ToolName,
+				Input: event.Input,
+			})
+
+			if !agent.IsToolAllowed(event.ToolName) {
+				log.Warn("Tool not allowed for agent", "tool", event.ToolName)
+				toolResults = append(toolResults, emitToolError(events, event.ToolID,
+					fmt.Sprintf("tool not allowed for this agent: %s", event.ToolName)))
+				continue
+			}
+
+			mcpClient, ok := modelMcpClients[serverName]
+			if !ok {
+				log.Warn("Server not found", "server", serverName)
+				toolResults = append(toolResults, emitToolError(events, event.ToolID,
+					fmt.Sprintf("server not found: %s", serverName)))
+				continue
+			}
+
+			var toolArgs map[string]interface{}
+			if err := json.Unmarshal(event.Input, &toolArgs); err != nil {
+				log.Warn("Error parsing tool arguments", "error", err)
+				toolResults = append(toolResults, emitToolError(events, event.ToolID,
+					fmt.Sprintf("error parsing tool arguments: %v", err)))
+				continue
+			}
+
+			events <- llm.Event{Type: llm.EventToolUseStart, ToolID: event.ToolID, ToolName: event.ToolName}
+
+			req := mcp.CallToolRequest{}
+			req.Params.Name = toolName
+			req.Params.Arguments = toolArgs
+			toolResultPtr, err := mcpClient.CallTool(context.Background(), req)
+			events <- llm.Event{Type: llm.EventToolUseEnd, ToolID: event.ToolID, ToolName: event.ToolName}
+			if err != nil {
+				toolResults = append(toolResults, emitToolError(events, event.ToolID,
+					fmt.Sprintf("Error calling tool %s: %v", toolName, err)))
+				continue
+			}
+
+			resultBlock := history.ContentBlock{
+				Type:      "tool_result",
+				ToolUseID: event.ToolID,
+				Content:   toolResultPtr.Content,
+			}
+			toolResults = append(toolResults, resultBlock)
+			events <- llm.Event{Type: llm.EventToolResult, ToolID: event.ToolID, Result: resultBlock.Content}
+
+		case llm.EventUsage:
+			inputTokens += event.InputTokens
+			outputTokens += event.OutputTokens
+
+		case llm.EventError:
+			events <- event
+			return
+		}
+	}
+
+	recordUsage(sessionID, modelUsageKey(agent), inputTokens, outputTokens)
+
+	*messages = append(*messages, history.HistoryMessage{
+		Role:         "assistant",
+		Content:      messageContent,
+		InputTokens:  inputTokens,
+		OutputTokens: outputTokens,
+	})
+
+	if len(toolResults) > 0 {
+		*messages = append(*messages, history.HistoryMessage{
+			Role:    "user",
+			Content: toolResults,
+		})
+
+		nested := make(chan llm.Event)
+		go streamPrompt("", agentName, sessionID, messages, nested)
+		for event := range nested {
+			switch event.Type {
+			case llm.EventUsage:
+				// Accumulated rather than forwarded: the figure that
+				// actually reaches the client is the running total,
+				// emitted just before EventDone below.
+				inputTokens += event.InputTokens
+				outputTokens += event.OutputTokens
+			case llm.EventDone:
+				events <- llm.Event{Type: llm.EventUsage, InputTokens: inputTokens, OutputTokens: outputTokens}
+				events <- event
+			default:
+				events <- event
+			}
+		}
+		return
+	}
+
+	if sessionStore == nil {
+		modelMessages = append(modelMessages, *messages...)
+	}
+	events <- llm.Event{Type: llm.EventUsage, InputTokens: inputTokens, OutputTokens: outputTokens}
+	events <- llm.Event{Type: llm.EventDone}
+}
+
+// emitToolError sends an error event for a failed tool call and returns
+// the matching tool_result block, so a dropped tool_use never goes
+// unanswered in message history.
+func emitToolError(events chan<- llm.Event, toolID, msg string) history.ContentBlock {
+	events <- llm.Event{Type: llm.EventError, ToolID: toolID, Err: msg}
+	return history.ContentBlock{
+		Type:      "tool_result",
+		ToolUseID: toolID,
+		Content: []history.ContentBlock{{
+			Type: "text",
+			Text: msg,
+		}},
+	}
+}
+
+func appendTextDelta(content []history.ContentBlock, delta string) []history.ContentBlock {
+	if len(content) > 0 && content[len(content)-1].Type == "text" {
+		content[len(content)-1].Text += delta
+		return content
+	}
+	return append(content, history.ContentBlock{Type: "text", Text: delta})
+}
@@ -0,0 +1,178 @@
+package server
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/log"
+)
+
+// PriceTier is the per-million-token price for a "provider:model" key,
+// loaded from the optional "pricing" block in the MCP config. Cost is
+// always recomputed from raw token counts at query time so changing a
+// tariff doesn't require re-running any conversation.
+type PriceTier struct {
+	InputPerMTok  float64 `json:"input_per_mtok"`
+	OutputPerMTok float64 `json:"output_per_mtok"`
+}
+
+type modelUsage struct {
+	InputTokens  int
+	OutputTokens int
+}
+
+// usageRecord is one turn's token counts, timestamped so a /usage query
+// can sum only the turns that happened after a given "since".
+type usageRecord struct {
+	InputTokens  int
+	OutputTokens int
+	At           time.Time
+}
+
+var (
+	usageMu     sync.Mutex
+	usageBySess = make(map[string]map[string][]usageRecord) // sessionID -> model -> turns
+	pricingMu   sync.Mutex
+	pricing     map[string]PriceTier
+)
+
+func setPricing(table map[string]PriceTier) {
+	pricingMu.Lock()
+	defer pricingMu.Unlock()
+	pricing = table
+}
+
+// recordUsage appends a turn's token counts to a session+model pair's
+// history. Turns are summed at query time rather than kept as a single
+// running total so GET /usage?since=... can report what actually
+// happened after that timestamp instead of an all-time total.
+//
+// When a sessionStore is open, usage is persisted there instead of the
+// in-memory map so a restart doesn't zero out totals, mirroring how
+// sessionStore replaces modelMessages as the source of truth for
+// conversation history.
+func recordUsage(sessionID, model string, inputTokens, outputTokens int) {
+	if inputTokens == 0 && outputTokens == 0 {
+		return
+	}
+
+	if sessionStore != nil {
+		if err := sessionStore.RecordUsage(sessionID, model, inputTokens, outputTokens); err != nil {
+			log.Warn("Error persisting usage", "error", err)
+		}
+		return
+	}
+
+	usageMu.Lock()
+	defer usageMu.Unlock()
+
+	byModel, ok := usageBySess[sessionID]
+	if !ok {
+		byModel = make(map[string][]usageRecord)
+		usageBySess[sessionID] = byModel
+	}
+
+	byModel[model] = append(byModel[model], usageRecord{
+		InputTokens:  inputTokens,
+		OutputTokens: outputTokens,
+		At:           time.Now().UTC(),
+	})
+}
+
+// usageReport is one model's aggregated usage and estimated cost for
+// GET /usage.
+type usageReport struct {
+	Model        string  `json:"model"`
+	Input        int     `json:"input"`
+	Output       int     `json:"output"`
+	Total        int     `json:"total"`
+	CostEstimate float64 `json:"cost_estimate,omitempty"`
+}
+
+func estimateCost(model string, inputTokens, outputTokens int) float64 {
+	pricingMu.Lock()
+	tier, ok := pricing[model]
+	pricingMu.Unlock()
+	if !ok {
+		return 0
+	}
+	return float64(inputTokens)/1_000_000*tier.InputPerMTok +
+		float64(outputTokens)/1_000_000*tier.OutputPerMTok
+}
+
+// usageHandler serves GET /usage?session=...&since=.... since is an
+// RFC3339 timestamp; only turns recorded after it are counted, so the
+// result is the usage that actually occurred in that window rather than
+// an all-time total for any session touched since then. Without a
+// session filter, every session's matching turns are summed per model.
+func (s *Server) usageHandler(w http.ResponseWriter, r *http.Request) {
+	sessionFilter := r.URL.Query().Get("session")
+
+	var since time.Time
+	if raw := r.URL.Query().Get("since"); raw != "" {
+		parsed, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			http.Error(w, "invalid since timestamp, expected RFC3339", http.StatusBadRequest)
+			return
+		}
+		since = parsed
+	}
+
+	totals := make(map[string]*modelUsage)
+
+	if sessionStore != nil {
+		records, err := sessionStore.Usage(sessionFilter)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		for _, record := range records {
+			if !since.IsZero() && record.CreatedAt.Before(since) {
+				continue
+			}
+			total, ok := totals[record.Model]
+			if !ok {
+				total = &modelUsage{}
+				totals[record.Model] = total
+			}
+			total.InputTokens += record.InputTokens
+			total.OutputTokens += record.OutputTokens
+		}
+	} else {
+		usageMu.Lock()
+		for sessionID, byModel := range usageBySess {
+			if sessionFilter != "" && sessionID != sessionFilter {
+				continue
+			}
+			for model, records := range byModel {
+				for _, record := range records {
+					if !since.IsZero() && record.At.Before(since) {
+						continue
+					}
+					total, ok := totals[model]
+					if !ok {
+						total = &modelUsage{}
+						totals[model] = total
+					}
+					total.InputTokens += record.InputTokens
+					total.OutputTokens += record.OutputTokens
+				}
+			}
+		}
+		usageMu.Unlock()
+	}
+
+	reports := make([]usageReport, 0, len(totals))
+	for model, entry := range totals {
+		reports = append(reports, usageReport{
+			Model:        model,
+			Input:        entry.InputTokens,
+			Output:       entry.OutputTokens,
+			Total:        entry.InputTokens + entry.OutputTokens,
+			CostEstimate: estimateCost(model, entry.InputTokens, entry.OutputTokens),
+		})
+	}
+
+	writeJSON(w, reports)
+}